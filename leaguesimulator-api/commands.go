@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"leaguesimulator/importer"
+	"leaguesimulator/league"
+)
+
+// runCommand runs a command-line subcommand instead of starting the server
+func runCommand(args []string) error {
+	switch args[0] {
+	case "import":
+		return importCommand(args[1:])
+	case "import-history":
+		return importHistoryCommand(args[1:])
+	case "backtest":
+		return backtestCommand(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q (available: import, import-history, backtest)", args[0])
+	}
+}
+
+// importCommand loads teams and optional fixtures from files, like POST /import
+func importCommand(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	teamsPath := flags.String("teams", "", "CSV or JSON file of teams (required)")
+	fixturesPath := flags.String("fixtures", "", "CSV or JSON file of fixtures and results")
+	format := flags.String("format", "", "csv or json, taken from the file extension when empty")
+	dryRun := flags.Bool("dry-run", false, "validate the files without changing the league")
+	removeTeams := flags.Bool("remove-teams", false, "remove teams that are not in the file together with their stored history")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *teamsPath == "" {
+		return errors.New("import: -teams is required")
+	}
+
+	teamsFile, teamsFormat, err := openImportPath(*teamsPath, *format)
+	if err != nil {
+		return err
+	}
+	defer teamsFile.Close()
+
+	var fixtures io.Reader
+	fixturesFormat := ""
+	if *fixturesPath != "" {
+		fixturesFile, detected, err := openImportPath(*fixturesPath, *format)
+		if err != nil {
+			return err
+		}
+		defer fixturesFile.Close()
+		fixtures, fixturesFormat = fixturesFile, detected
+	}
+
+	var manager league.LeagueManager
+	manager.InitLeague()
+	summary, err := manager.ImportFiles(teamsFile, teamsFormat, fixtures, fixturesFormat, league.ImportOptions{
+		DryRun:      *dryRun,
+		RemoveTeams: *removeTeams,
+	})
+
+	var validation *importer.ValidationError
+	if errors.As(err, &validation) {
+		for _, row := range validation.Rows {
+			log.Printf("  %s row %d %s: %s", row.File, row.Row, row.Field, row.Message)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return printJSON(summary)
+}
+
+// importHistoryCommand loads football-data style results files as historical
+// seasons, like POST /import/history
+func importHistoryCommand(args []string) error {
+	flags := flag.NewFlagSet("import-history", flag.ContinueOnError)
+	firstSeason := flags.Int("first-season", 0, "negative number of the first imported season (default: counting back from the earliest stored season)")
+	dryRun := flags.Bool("dry-run", false, "check the files without storing anything")
+	aliases := aliasFlag{}
+	flags.Var(aliases, "alias", `map a file's team name to a league team, as "Name=Team" (repeatable)`)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errors.New("import-history: give one or more results CSV files")
+	}
+
+	var files []io.Reader
+	for _, path := range flags.Args() {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		files = append(files, file)
+	}
+
+	var manager league.LeagueManager
+	manager.InitLeague()
+	summary, err := manager.ImportHistoryFiles(files, league.HistoryImportOptions{
+		FirstSeason: *firstSeason,
+		Aliases:     aliases,
+		DryRun:      *dryRun,
+	})
+
+	var validation *importer.ValidationError
+	if errors.As(err, &validation) {
+		for _, row := range validation.Rows {
+			log.Printf("  line %d %s: %s", row.Line, row.Field, row.Message)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return printJSON(summary)
+}
+
+// aliasFlag collects repeated -alias "Name=Team" flags
+type aliasFlag map[string]string
+
+func (a aliasFlag) String() string {
+	return fmt.Sprint(map[string]string(a))
+}
+
+func (a aliasFlag) Set(value string) error {
+	name, team, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(name) == "" || strings.TrimSpace(team) == "" {
+		return fmt.Errorf("alias %q must look like Name=Team", value)
+	}
+	a[strings.TrimSpace(name)] = strings.TrimSpace(team)
+	return nil
+}
+
+// backtestCommand replays stored seasons and saves a report per
+// configuration, like POST /backtests
+func backtestCommand(args []string) error {
+	flags := flag.NewFlagSet("backtest", flag.ContinueOnError)
+	var configs configFlag
+	flags.Var(&configs, "config", `model configuration as model[:half_life_weeks[:regularization]], e.g. "predictor", "poisson:19" or "base_rate" (repeatable)`)
+	seasonList := flags.String("seasons", "", "comma-separated seasons to replay (default: every finished season)")
+	minTraining := flags.Int("min-training", 0, "earlier matches needed before a week is predicted")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	options := league.BacktestOptions{Configs: configs, MinTrainingMatches: *minTraining}
+	for _, value := range strings.Split(*seasonList, ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		season, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("backtest: %q is not a season number", value)
+		}
+		options.Seasons = append(options.Seasons, season)
+	}
+
+	var manager league.LeagueManager
+	manager.InitLeague()
+	reports, err := manager.RunBacktest(options)
+	if err != nil {
+		return err
+	}
+	for _, report := range reports {
+		log.Printf("  #%d %-24s accuracy %.4f  brier %.4f  log loss %.4f  (%d predictions)",
+			report.ID, report.Config.Name, report.Overall.Accuracy, report.Overall.BrierScore, report.Overall.LogLoss, report.Overall.Predictions)
+	}
+	return printJSON(reports)
+}
+
+// configFlag collects repeated -config flags
+type configFlag []league.BacktestConfig
+
+func (c *configFlag) String() string {
+	return fmt.Sprint(*c)
+}
+
+func (c *configFlag) Set(value string) error {
+	config, err := league.ParseBacktestConfig(value)
+	if err != nil {
+		return err
+	}
+	*c = append(*c, config)
+	return nil
+}
+
+func openImportPath(path, format string) (*os.File, string, error) {
+	detected, err := importer.DetectFormat(format, path, "")
+	if err != nil {
+		return nil, "", err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return file, detected, nil
+}
+
+// printJSON writes a command's result to standard output
+func printJSON(value interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
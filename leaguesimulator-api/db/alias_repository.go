@@ -0,0 +1,45 @@
+package db
+
+import (
+	"leaguesimulator/models"
+)
+
+// GetTeamAliases returns every stored team alias ordered by team and alias
+func GetTeamAliases() ([]models.TeamAlias, error) {
+	rows, err := DB.Query(`SELECT alias, team_name FROM team_aliases ORDER BY team_name, alias`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aliases := []models.TeamAlias{}
+	for rows.Next() {
+		var alias models.TeamAlias
+		if err := rows.Scan(&alias.Alias, &alias.Team); err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, alias)
+	}
+	return aliases, rows.Err()
+}
+
+// SaveTeamAlias points an alias at a team, replacing an earlier mapping
+func SaveTeamAlias(alias models.TeamAlias) error {
+	query := `
+		INSERT INTO team_aliases (alias, team_name)
+		VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE team_name = VALUES(team_name)
+	`
+	_, err := DB.Exec(query, alias.Alias, alias.Team)
+	return err
+}
+
+// DeleteTeamAlias removes an alias and reports whether it existed
+func DeleteTeamAlias(alias string) (bool, error) {
+	result, err := DB.Exec(`DELETE FROM team_aliases WHERE alias = ?`, alias)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"database/sql"
+
+	"leaguesimulator/models"
+)
+
+// EditMatchResult stores a match's new score in matches and in its
+// historical_matches row, replaces its scorers with the given ones and records
+// the edit in the audit log, all in one transaction
+func EditMatchResult(match models.Match, edit models.MatchEdit, goals []models.Goal) (int, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO match_result_edits
+		(match_id, old_home_goals, old_away_goals, new_home_goals, new_away_goals, reason, actor, revert_of)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := tx.Exec(query,
+		edit.MatchID,
+		edit.OldHomeGoals,
+		edit.OldAwayGoals,
+		edit.NewHomeGoals,
+		edit.NewAwayGoals,
+		edit.Reason,
+		edit.Actor,
+		nullInt(edit.RevertOf),
+	)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM match_goals WHERE match_id = ?`, match.ID); err != nil {
+		return 0, err
+	}
+	if err := insertMatchGoals(tx, match.ID, goals); err != nil {
+		return 0, err
+	}
+	_, err = tx.Exec(`UPDATE matches SET home_goals = ?, away_goals = ?, played = ? WHERE id = ?`,
+		match.HomeGoals, match.AwayGoals, match.Played, match.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	query = `
+		UPDATE historical_matches
+		SET home_goals = ?, away_goals = ?
+		WHERE season = ? AND week = ? AND home_team_name = ? AND away_team_name = ?
+	`
+	_, err = tx.Exec(query, match.HomeGoals, match.AwayGoals, CurrentSeason, match.Week, match.HomeTeam, match.AwayTeam)
+	if err != nil {
+		return 0, err
+	}
+	return int(id), tx.Commit()
+}
+
+// GetMatchEdits returns the audit log of a match, oldest change first
+func GetMatchEdits(matchID int) ([]models.MatchEdit, error) {
+	query := `
+		SELECT id, match_id, old_home_goals, old_away_goals, new_home_goals, new_away_goals,
+		       reason, actor, revert_of, edited_at
+		FROM match_result_edits
+		WHERE match_id = ?
+		ORDER BY id
+	`
+	rows, err := DB.Query(query, matchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edits := []models.MatchEdit{}
+	for rows.Next() {
+		edit, err := scanMatchEdit(rows)
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, edit)
+	}
+	return edits, nil
+}
+
+// GetMatchEdit loads one audit entry. It returns sql.ErrNoRows when it does not exist.
+func GetMatchEdit(editID int) (*models.MatchEdit, error) {
+	query := `
+		SELECT id, match_id, old_home_goals, old_away_goals, new_home_goals, new_away_goals,
+		       reason, actor, revert_of, edited_at
+		FROM match_result_edits
+		WHERE id = ?
+	`
+	edit, err := scanMatchEdit(DB.QueryRow(query, editID))
+	if err != nil {
+		return nil, err
+	}
+	return &edit, nil
+}
+
+func scanMatchEdit(row rowScanner) (models.MatchEdit, error) {
+	var edit models.MatchEdit
+	var revertOf sql.NullInt64
+	err := row.Scan(
+		&edit.ID,
+		&edit.MatchID,
+		&edit.OldHomeGoals,
+		&edit.OldAwayGoals,
+		&edit.NewHomeGoals,
+		&edit.NewAwayGoals,
+		&edit.Reason,
+		&edit.Actor,
+		&revertOf,
+		&edit.EditedAt,
+	)
+	edit.RevertOf = int(revertOf.Int64)
+	return edit, err
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"leaguesimulator/models"
+)
+
+// CreateBacktestReports saves the reports of one run in one transaction and
+// returns their IDs in the same order
+func CreateBacktestReports(reports []models.BacktestReport) ([]int, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO backtest_reports (name, model, predictions, accuracy, brier_score, log_loss, document)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	ids := make([]int, len(reports))
+	for i, report := range reports {
+		result, err := tx.Exec(query,
+			report.Name,
+			report.Model,
+			report.Predictions,
+			report.Accuracy,
+			report.BrierScore,
+			report.LogLoss,
+			string(report.Document),
+		)
+		if err != nil {
+			return nil, err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = int(id)
+	}
+	return ids, tx.Commit()
+}
+
+// GetAllBacktestReports lists saved backtest reports without their documents, newest first
+func GetAllBacktestReports() ([]models.BacktestReport, error) {
+	query := `
+		SELECT id, name, model, predictions, accuracy, brier_score, log_loss, created_at
+		FROM backtest_reports
+		ORDER BY id DESC
+	`
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []models.BacktestReport{}
+	for rows.Next() {
+		var report models.BacktestReport
+		err := rows.Scan(
+			&report.ID,
+			&report.Name,
+			&report.Model,
+			&report.Predictions,
+			&report.Accuracy,
+			&report.BrierScore,
+			&report.LogLoss,
+			&report.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, rows.Err()
+}
+
+// GetBacktestReport loads a report with its document. It returns
+// sql.ErrNoRows when the report does not exist.
+func GetBacktestReport(reportID int) (*models.BacktestReport, error) {
+	var report models.BacktestReport
+	var document string
+	query := `
+		SELECT id, name, model, predictions, accuracy, brier_score, log_loss, created_at, document
+		FROM backtest_reports
+		WHERE id = ?
+	`
+	err := DB.QueryRow(query, reportID).Scan(
+		&report.ID,
+		&report.Name,
+		&report.Model,
+		&report.Predictions,
+		&report.Accuracy,
+		&report.BrierScore,
+		&report.LogLoss,
+		&report.CreatedAt,
+		&document,
+	)
+	if err != nil {
+		return nil, err
+	}
+	report.Document = []byte(document)
+	return &report, nil
+}
@@ -0,0 +1,299 @@
+package db
+
+import (
+	"database/sql"
+
+	"leaguesimulator/models"
+)
+
+// CreateCup stores a cup with its entrants in one transaction
+func CreateCup(cup models.Cup) (int, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := insertCup(tx, &cup); err != nil {
+		return 0, err
+	}
+	return cup.ID, tx.Commit()
+}
+
+// insertCup adds a cup and its entrants inside a transaction, filling in the cup ID
+func insertCup(tx *sql.Tx, cup *models.Cup) error {
+	query := `
+		INSERT INTO cups (name, legs, draw_method, current_round, status)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	result, err := tx.Exec(query,
+		cup.Name,
+		cup.Legs,
+		cup.DrawMethod,
+		cup.CurrentRound,
+		cup.Status,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	cup.ID = int(id)
+
+	for seed, teamName := range cup.Entrants {
+		_, err := tx.Exec(`INSERT INTO cup_entrants (cup_id, team_name, seed) VALUES (?, ?, ?)`,
+			cup.ID, teamName, seed+1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func GetAllCups() ([]models.Cup, error) {
+	query := `SELECT id, name, legs, draw_method, current_round, status, champion_name FROM cups ORDER BY id`
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cups []models.Cup
+	for rows.Next() {
+		cup, err := scanCup(rows)
+		if err != nil {
+			return nil, err
+		}
+		cups = append(cups, cup)
+	}
+	return cups, nil
+}
+
+// GetCup loads a cup with its entrants and every drawn tie. It returns
+// sql.ErrNoRows when the cup does not exist.
+func GetCup(cupID int) (*models.Cup, error) {
+	query := `SELECT id, name, legs, draw_method, current_round, status, champion_name FROM cups WHERE id = ?`
+	cup, err := scanCup(DB.QueryRow(query, cupID))
+	if err != nil {
+		return nil, err
+	}
+
+	entrantRows, err := DB.Query(`SELECT team_name FROM cup_entrants WHERE cup_id = ? ORDER BY seed`, cupID)
+	if err != nil {
+		return nil, err
+	}
+	defer entrantRows.Close()
+
+	for entrantRows.Next() {
+		var teamName string
+		if err := entrantRows.Scan(&teamName); err != nil {
+			return nil, err
+		}
+		cup.Entrants = append(cup.Entrants, teamName)
+	}
+
+	ties, err := GetCupTies(cupID)
+	if err != nil {
+		return nil, err
+	}
+	cup.Ties = ties
+
+	return &cup, nil
+}
+
+func GetCupTies(cupID int) ([]models.CupTie, error) {
+	query := `
+		SELECT id, cup_id, round, slot, home_team_name, away_team_name,
+		       leg1_home_goals, leg1_away_goals, leg2_home_goals, leg2_away_goals,
+		       extra_time_home_goals, extra_time_away_goals, penalties_home, penalties_away,
+		       winner_name, is_bye, played
+		FROM cup_ties
+		WHERE cup_id = ?
+		ORDER BY round, slot
+	`
+	rows, err := DB.Query(query, cupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ties []models.CupTie
+	for rows.Next() {
+		var tie models.CupTie
+		var awayTeam, winner sql.NullString
+		var leg1Home, leg1Away, leg2Home, leg2Away sql.NullInt64
+		var etHome, etAway, penHome, penAway sql.NullInt64
+
+		err := rows.Scan(
+			&tie.ID,
+			&tie.CupID,
+			&tie.Round,
+			&tie.Slot,
+			&tie.HomeTeam,
+			&awayTeam,
+			&leg1Home,
+			&leg1Away,
+			&leg2Home,
+			&leg2Away,
+			&etHome,
+			&etAway,
+			&penHome,
+			&penAway,
+			&winner,
+			&tie.Bye,
+			&tie.Played,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		tie.AwayTeam = awayTeam.String
+		tie.Winner = winner.String
+		tie.Legs = []models.CupLeg{}
+		if leg1Home.Valid && leg1Away.Valid {
+			tie.Legs = append(tie.Legs, models.CupLeg{
+				HomeTeam:  tie.HomeTeam,
+				AwayTeam:  tie.AwayTeam,
+				HomeGoals: int(leg1Home.Int64),
+				AwayGoals: int(leg1Away.Int64),
+			})
+		}
+		if leg2Home.Valid && leg2Away.Valid {
+			tie.Legs = append(tie.Legs, models.CupLeg{
+				HomeTeam:  tie.AwayTeam,
+				AwayTeam:  tie.HomeTeam,
+				HomeGoals: int(leg2Home.Int64),
+				AwayGoals: int(leg2Away.Int64),
+			})
+		}
+		if etHome.Valid && etAway.Valid {
+			tie.ExtraTime = &models.ScoreLine{Home: int(etHome.Int64), Away: int(etAway.Int64)}
+		}
+		if penHome.Valid && penAway.Valid {
+			tie.Penalties = &models.ScoreLine{Home: int(penHome.Int64), Away: int(penAway.Int64)}
+		}
+
+		ties = append(ties, tie)
+	}
+	return ties, nil
+}
+
+// SaveCupRound stores the results of the played round, the newly drawn ties
+// and the cup's progress in one transaction, filling in the new tie IDs.
+// playedRound is zero when no round was played.
+func SaveCupRound(cup *models.Cup, playedRound int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := saveCupRound(tx, cup, playedRound); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// saveCupRound is SaveCupRound inside a transaction. Ties without an ID are
+// the newly drawn ones.
+func saveCupRound(tx *sql.Tx, cup *models.Cup, playedRound int) error {
+	insertQuery := `
+		INSERT INTO cup_ties (cup_id, round, slot, home_team_name, away_team_name, winner_name, is_bye, played)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	updateQuery := `
+		UPDATE cup_ties
+		SET leg1_home_goals = ?, leg1_away_goals = ?, leg2_home_goals = ?, leg2_away_goals = ?,
+		    extra_time_home_goals = ?, extra_time_away_goals = ?, penalties_home = ?, penalties_away = ?,
+		    winner_name = ?, played = ?
+		WHERE id = ?
+	`
+
+	for i := range cup.Ties {
+		tie := &cup.Ties[i]
+		if tie.ID == 0 {
+			result, err := tx.Exec(insertQuery,
+				cup.ID,
+				tie.Round,
+				tie.Slot,
+				tie.HomeTeam,
+				nullString(tie.AwayTeam),
+				nullString(tie.Winner),
+				tie.Bye,
+				tie.Played,
+			)
+			if err != nil {
+				return err
+			}
+			id, err := result.LastInsertId()
+			if err != nil {
+				return err
+			}
+			tie.ID = int(id)
+			tie.CupID = cup.ID
+			continue
+		}
+		if tie.Round != playedRound {
+			continue
+		}
+
+		var legGoals [4]sql.NullInt64
+		for i, leg := range tie.Legs {
+			if i > 1 {
+				break
+			}
+			legGoals[i*2] = sql.NullInt64{Int64: int64(leg.HomeGoals), Valid: true}
+			legGoals[i*2+1] = sql.NullInt64{Int64: int64(leg.AwayGoals), Valid: true}
+		}
+		_, err := tx.Exec(updateQuery,
+			legGoals[0],
+			legGoals[1],
+			legGoals[2],
+			legGoals[3],
+			nullScore(tie.ExtraTime, true),
+			nullScore(tie.ExtraTime, false),
+			nullScore(tie.Penalties, true),
+			nullScore(tie.Penalties, false),
+			nullString(tie.Winner),
+			tie.Played,
+			tie.ID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	query := `UPDATE cups SET current_round = ?, status = ?, champion_name = ? WHERE id = ?`
+	_, err := tx.Exec(query, cup.CurrentRound, cup.Status, nullString(cup.Champion), cup.ID)
+	return err
+}
+
+func scanCup(row rowScanner) (models.Cup, error) {
+	var cup models.Cup
+	var champion sql.NullString
+	err := row.Scan(
+		&cup.ID,
+		&cup.Name,
+		&cup.Legs,
+		&cup.DrawMethod,
+		&cup.CurrentRound,
+		&cup.Status,
+		&champion,
+	)
+	cup.Champion = champion.String
+	return cup, err
+}
+
+func nullScore(score *models.ScoreLine, home bool) sql.NullInt64 {
+	if score == nil {
+		return sql.NullInt64{}
+	}
+	if home {
+		return sql.NullInt64{Int64: int64(score.Home), Valid: true}
+	}
+	return sql.NullInt64{Int64: int64(score.Away), Valid: true}
+}
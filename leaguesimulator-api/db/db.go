@@ -52,6 +52,19 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func nullInt(value int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(value), Valid: value != 0}
+}
+
 func GetHistoricalMatches() ([]map[string]interface{}, error) {
 	query := `SELECT season, week, home_team_name, away_team_name, home_goals, away_goals
               FROM historical_matches
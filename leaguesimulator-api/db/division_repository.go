@@ -0,0 +1,236 @@
+package db
+
+import "leaguesimulator/models"
+
+// CreateDivision stores a division with its teams as members for the given
+// season in one transaction
+func CreateDivision(season int, division models.Division) (int, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO divisions (name, tier, promotion_spots, relegation_spots, playoff_spots)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	result, err := tx.Exec(query,
+		division.Name,
+		division.Tier,
+		division.PromotionSpots,
+		division.RelegationSpots,
+		division.PlayoffSpots,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	memberQuery := `INSERT INTO division_members (season, division_id, team_name) VALUES (?, ?, ?)`
+	for _, teamName := range division.Teams {
+		if _, err := tx.Exec(memberQuery, season, id, teamName); err != nil {
+			return 0, err
+		}
+	}
+	return int(id), tx.Commit()
+}
+
+func GetAllDivisions() ([]models.Division, error) {
+	query := `SELECT id, name, tier, promotion_spots, relegation_spots, playoff_spots FROM divisions ORDER BY tier`
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var divisions []models.Division
+	for rows.Next() {
+		var division models.Division
+		err := rows.Scan(
+			&division.ID,
+			&division.Name,
+			&division.Tier,
+			&division.PromotionSpots,
+			&division.RelegationSpots,
+			&division.PlayoffSpots,
+		)
+		if err != nil {
+			return nil, err
+		}
+		divisions = append(divisions, division)
+	}
+	return divisions, nil
+}
+
+// GetLatestDivisionSeason returns the newest season with division members, or 1 when there is none
+func GetLatestDivisionSeason() (int, error) {
+	var season int
+	err := DB.QueryRow(`SELECT COALESCE(MAX(season), 1) FROM division_members`).Scan(&season)
+	return season, err
+}
+
+// GetDivisionMembers returns the teams of every division in a season, keyed by division ID
+func GetDivisionMembers(season int) (map[int][]string, error) {
+	query := `SELECT division_id, team_name FROM division_members WHERE season = ? ORDER BY division_id, team_name`
+	rows, err := DB.Query(query, season)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := make(map[int][]string)
+	for rows.Next() {
+		var divisionID int
+		var teamName string
+		if err := rows.Scan(&divisionID, &teamName); err != nil {
+			return nil, err
+		}
+		members[divisionID] = append(members[divisionID], teamName)
+	}
+	return members, nil
+}
+
+// SaveDivisionMatches stores the results of a whole division season in one transaction
+func SaveDivisionMatches(matches []models.DivisionMatch) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO division_matches (season, division_id, week, home_team_name, away_team_name, home_goals, away_goals)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	for _, match := range matches {
+		_, err := tx.Exec(query,
+			match.Season,
+			match.DivisionID,
+			match.Week,
+			match.HomeTeam,
+			match.AwayTeam,
+			match.HomeGoals,
+			match.AwayGoals,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func GetDivisionMatches(season int) ([]models.DivisionMatch, error) {
+	query := `
+		SELECT id, season, division_id, week, home_team_name, away_team_name, home_goals, away_goals
+		FROM division_matches
+		WHERE season = ?
+		ORDER BY division_id, week, id
+	`
+	rows, err := DB.Query(query, season)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []models.DivisionMatch
+	for rows.Next() {
+		var match models.DivisionMatch
+		err := rows.Scan(
+			&match.ID,
+			&match.Season,
+			&match.DivisionID,
+			&match.Week,
+			&match.HomeTeam,
+			&match.AwayTeam,
+			&match.HomeGoals,
+			&match.AwayGoals,
+		)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// SaveDivisionRollover stores the division members of a new season and the
+// movements that produced them in one transaction
+func SaveDivisionRollover(season int, members map[int][]string, movements []models.DivisionMovement) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	memberQuery := `INSERT INTO division_members (season, division_id, team_name) VALUES (?, ?, ?)`
+	for divisionID, teamNames := range members {
+		for _, teamName := range teamNames {
+			if _, err := tx.Exec(memberQuery, season, divisionID, teamName); err != nil {
+				return err
+			}
+		}
+	}
+
+	movementQuery := `
+		INSERT INTO division_movements (season, team_name, from_division_id, to_division_id, movement, reason)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	for _, movement := range movements {
+		_, err := tx.Exec(movementQuery,
+			movement.Season,
+			movement.TeamName,
+			movement.FromDivisionID,
+			movement.ToDivisionID,
+			movement.Movement,
+			movement.Reason,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetDivisionMovements returns the moves decided at the end of a season, or of every season when season is 0
+func GetDivisionMovements(season int) ([]models.DivisionMovement, error) {
+	query := `
+		SELECT m.id, m.season, m.team_name, m.from_division_id, f.name, m.to_division_id, t.name, m.movement, m.reason
+		FROM division_movements m
+		JOIN divisions f ON f.id = m.from_division_id
+		JOIN divisions t ON t.id = m.to_division_id
+		WHERE ? = 0 OR m.season = ?
+		ORDER BY m.season, f.tier, m.movement, m.id
+	`
+	rows, err := DB.Query(query, season, season)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := []models.DivisionMovement{}
+	for rows.Next() {
+		var movement models.DivisionMovement
+		err := rows.Scan(
+			&movement.ID,
+			&movement.Season,
+			&movement.TeamName,
+			&movement.FromDivisionID,
+			&movement.FromDivision,
+			&movement.ToDivisionID,
+			&movement.ToDivision,
+			&movement.Movement,
+			&movement.Reason,
+		)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+	return movements, nil
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"database/sql"
+
+	"leaguesimulator/models"
+)
+
+// SaveMatchGoals replaces the scorers of a match
+func SaveMatchGoals(matchID int, goals []models.Goal) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM match_goals WHERE match_id = ?`, matchID); err != nil {
+		return err
+	}
+
+	if err := insertMatchGoals(tx, matchID, goals); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertMatchGoals adds the scorers of a match inside a transaction
+func insertMatchGoals(tx *sql.Tx, matchID int, goals []models.Goal) error {
+	query := `
+		INSERT INTO match_goals (match_id, team_name, player_name, minute, own_goal, penalty)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	for _, goal := range goals {
+		_, err := tx.Exec(query,
+			matchID,
+			goal.Team,
+			goal.Player,
+			nullInt(goal.Minute),
+			goal.OwnGoal,
+			goal.Penalty,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func GetMatchGoals(matchID int) ([]models.Goal, error) {
+	query := `
+		SELECT id, match_id, team_name, player_name, minute, own_goal, penalty
+		FROM match_goals
+		WHERE match_id = ?
+		ORDER BY COALESCE(minute, 999), id
+	`
+	rows, err := DB.Query(query, matchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	goals := []models.Goal{}
+	for rows.Next() {
+		var goal models.Goal
+		var minute sql.NullInt64
+		err := rows.Scan(
+			&goal.ID,
+			&goal.MatchID,
+			&goal.Team,
+			&goal.Player,
+			&minute,
+			&goal.OwnGoal,
+			&goal.Penalty,
+		)
+		if err != nil {
+			return nil, err
+		}
+		goal.Minute = int(minute.Int64)
+		goals = append(goals, goal)
+	}
+	return goals, nil
+}
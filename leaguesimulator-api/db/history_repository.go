@@ -0,0 +1,104 @@
+package db
+
+import (
+	"leaguesimulator/models"
+)
+
+// GetHistoricalMatchList returns every archived match ordered by season and week
+func GetHistoricalMatchList() ([]models.HistoricalMatch, error) {
+	query := `
+		SELECT id, season, week, home_team_name, away_team_name, home_goals, away_goals
+		FROM historical_matches
+		ORDER BY season, week, id
+	`
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []models.HistoricalMatch{}
+	for rows.Next() {
+		var match models.HistoricalMatch
+		err := rows.Scan(
+			&match.ID,
+			&match.Season,
+			&match.Week,
+			&match.HomeTeam,
+			&match.AwayTeam,
+			&match.HomeGoals,
+			&match.AwayGoals,
+		)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	return matches, rows.Err()
+}
+
+// ImportHistoricalSeasons records imported seasons and adds their matches to
+// the archive in one transaction. A stale record with the same label, left
+// behind when its matches were deleted, is replaced.
+func ImportHistoricalSeasons(seasons []models.ImportedSeason, matches []models.HistoricalMatch) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	seasonQuery := `
+		REPLACE INTO imported_seasons (season, label, matches, weeks, first_date, last_date)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	for _, season := range seasons {
+		_, err := tx.Exec(seasonQuery, season.Season, season.Label, season.Matches, season.Weeks, season.FirstDate, season.LastDate)
+		if err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO historical_matches (season, week, home_team_name, away_team_name, home_goals, away_goals)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	for _, match := range matches {
+		_, err := tx.Exec(query, match.Season, match.Week, match.HomeTeam, match.AwayTeam, match.HomeGoals, match.AwayGoals)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetImportedSeasons returns every season loaded from a results file, oldest first
+func GetImportedSeasons() ([]models.ImportedSeason, error) {
+	query := `
+		SELECT season, label, matches, weeks, first_date, last_date
+		FROM imported_seasons
+		ORDER BY season
+	`
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seasons := []models.ImportedSeason{}
+	for rows.Next() {
+		var season models.ImportedSeason
+		err := rows.Scan(
+			&season.Season,
+			&season.Label,
+			&season.Matches,
+			&season.Weeks,
+			&season.FirstDate,
+			&season.LastDate,
+		)
+		if err != nil {
+			return nil, err
+		}
+		seasons = append(seasons, season)
+	}
+	return seasons, rows.Err()
+}
@@ -1,27 +1,54 @@
 package db
 
-import "leaguesimulator/models"
+import (
+	"database/sql"
 
-func SaveMatch(match models.Match) error {
-	query := `
-		INSERT INTO matches (week, home_team_name, away_team_name, home_goals, away_goals, played)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`
+	"leaguesimulator/models"
+)
 
-	_, err := DB.Exec(query,
-		match.Week,
-		match.HomeTeam,
-		match.AwayTeam,
-		match.HomeGoals,
-		match.AwayGoals,
-		match.Played,
-	)
+// ReplaceMatches deletes every match and stores the given fixtures instead,
+// all in one transaction
+func ReplaceMatches(matches []models.Match) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	return err
+	if _, err := tx.Exec(`DELETE FROM matches`); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO matches (week, home_team_name, away_team_name, home_goals, away_goals, played, kick_off, venue_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	for _, match := range matches {
+		_, err := tx.Exec(query,
+			match.Week,
+			match.HomeTeam,
+			match.AwayTeam,
+			match.HomeGoals,
+			match.AwayGoals,
+			match.Played,
+			match.KickOff,
+			nullInt(match.VenueID),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
 func GetAllMatches() ([]models.Match, error) {
-	query := `SELECT id, week, home_team_name, away_team_name, home_goals, away_goals, played FROM matches ORDER BY week, id`
+	query := `
+		SELECT m.id, m.week, m.home_team_name, m.away_team_name, m.home_goals, m.away_goals, m.played,
+		       m.kick_off, m.venue_id, v.name, m.postponed, m.postponement_reason
+		FROM matches m
+		LEFT JOIN venues v ON v.id = m.venue_id
+		ORDER BY m.week, m.id
+	`
 	rows, err := DB.Query(query)
 	if err != nil {
 		return nil, err
@@ -30,16 +57,7 @@ func GetAllMatches() ([]models.Match, error) {
 
 	var matches []models.Match
 	for rows.Next() {
-		var match models.Match
-		err := rows.Scan(
-			&match.ID,
-			&match.Week,
-			&match.HomeTeam,
-			&match.AwayTeam,
-			&match.HomeGoals,
-			&match.AwayGoals,
-			&match.Played,
-		)
+		match, err := scanMatch(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -48,39 +66,179 @@ func GetAllMatches() ([]models.Match, error) {
 	return matches, nil
 }
 
-func UpdateMatch(match models.Match) error {
+// UpdateMatchSchedule stores a new kick-off time and venue for a fixture
+func UpdateMatchSchedule(match models.Match) error {
+	query := `UPDATE matches SET week = ?, kick_off = ?, venue_id = ? WHERE id = ?`
+	_, err := DB.Exec(query, match.Week, match.KickOff, nullInt(match.VenueID), match.ID)
+	return err
+}
+
+// UpdateMatchPostponement stores whether a fixture is postponed, why, and when it is now played
+func UpdateMatchPostponement(match models.Match) error {
+	query := `UPDATE matches SET postponed = ?, postponement_reason = ?, week = ?, kick_off = ? WHERE id = ?`
+	_, err := DB.Exec(query, match.Postponed, nullString(match.PostponementReason), match.Week, match.KickOff, match.ID)
+	return err
+}
+
+func scanMatch(row rowScanner) (models.Match, error) {
+	var match models.Match
+	var kickOff sql.NullTime
+	var venueID sql.NullInt64
+	var venue, reason sql.NullString
+	err := row.Scan(
+		&match.ID,
+		&match.Week,
+		&match.HomeTeam,
+		&match.AwayTeam,
+		&match.HomeGoals,
+		&match.AwayGoals,
+		&match.Played,
+		&kickOff,
+		&venueID,
+		&venue,
+		&match.Postponed,
+		&reason,
+	)
+	if kickOff.Valid {
+		match.KickOff = &kickOff.Time
+	}
+	match.VenueID = int(venueID.Int64)
+	match.Venue = venue.String
+	match.PostponementReason = reason.String
+	return match, err
+}
+
+// GetMatchByID loads a single match by its primary key. It returns
+// sql.ErrNoRows when the match does not exist.
+func GetMatchByID(matchID int) (*models.Match, error) {
+	query := `
+		SELECT m.id, m.week, m.home_team_name, m.away_team_name, m.home_goals, m.away_goals, m.played,
+		       m.kick_off, m.venue_id, v.name, m.postponed, m.postponement_reason
+		FROM matches m
+		LEFT JOIN venues v ON v.id = m.venue_id
+		WHERE m.id = ?
+	`
+	match, err := scanMatch(DB.QueryRow(query, matchID))
+	if err != nil {
+		return nil, err
+	}
+	return &match, nil
+}
+
+// UpdateMatchByID stores the score and played flag of a match by its primary key
+func UpdateMatchByID(match models.Match) error {
 	query := `
-		UPDATE matches 
+		UPDATE matches
 		SET home_goals = ?, away_goals = ?, played = ?
-		WHERE week = ? AND home_team_name = ? AND away_team_name = ?
+		WHERE id = ?
 	`
 
 	_, err := DB.Exec(query,
 		match.HomeGoals,
 		match.AwayGoals,
 		match.Played,
-		match.Week,
-		match.HomeTeam,
-		match.AwayTeam,
+		match.ID,
 	)
 
 	return err
 }
 
-func ClearAllMatches() error {
-	query := `DELETE FROM matches`
-	_, err := DB.Exec(query)
-	return err
+// CurrentSeason is the historical_matches season the running league writes
+// to. The league loads it from its settings on start and raises it when a new
+// season begins.
+var CurrentSeason = 1
+
+// RewindSummary counts what RewindMatches removed
+type RewindSummary struct {
+	MatchesReset       int64 `json:"matches_reset"`
+	HistoricalRemoved  int64 `json:"historical_matches_removed"`
+	PredictionsRemoved int64 `json:"predictions_removed"`
+}
+
+// RewindMatches turns every match after the given week back into an unplayed
+// fixture and removes its scorers, result edits, historical row and the
+// predictions submitted after that week, all in one transaction
+func RewindMatches(week int) (RewindSummary, error) {
+	var summary RewindSummary
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return summary, err
+	}
+	defer tx.Rollback()
+
+	cleanups := []string{
+		`DELETE g FROM match_goals g JOIN matches m ON m.id = g.match_id WHERE m.week > ?`,
+		`DELETE e FROM match_result_edits e JOIN matches m ON m.id = e.match_id WHERE m.week > ?`,
+	}
+	for _, query := range cleanups {
+		if _, err := tx.Exec(query, week); err != nil {
+			return summary, err
+		}
+	}
+
+	result, err := tx.Exec(`UPDATE matches SET home_goals = 0, away_goals = 0, played = FALSE WHERE week > ? AND played = TRUE`, week)
+	if err != nil {
+		return summary, err
+	}
+	summary.MatchesReset, _ = result.RowsAffected()
+
+	result, err = tx.Exec(`DELETE FROM historical_matches WHERE season = ? AND week > ?`, CurrentSeason, week)
+	if err != nil {
+		return summary, err
+	}
+	summary.HistoricalRemoved, _ = result.RowsAffected()
+
+	result, err = tx.Exec(`DELETE FROM predictions WHERE week_submitted > ?`, week)
+	if err != nil {
+		return summary, err
+	}
+	summary.PredictionsRemoved, _ = result.RowsAffected()
+
+	return summary, tx.Commit()
 }
 
 // match_repository.go
+// RecordMatchResult stores the first result of a fixture together with its
+// scorers and its historical_matches row, all in one transaction
+func RecordMatchResult(match models.Match, goals []models.Goal) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`UPDATE matches SET home_goals = ?, away_goals = ?, played = ? WHERE id = ?`,
+		match.HomeGoals, match.AwayGoals, match.Played, match.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM match_goals WHERE match_id = ?`, match.ID); err != nil {
+		return err
+	}
+	if err := insertMatchGoals(tx, match.ID, goals); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO historical_matches
+		(season, week, home_team_name, away_team_name, home_goals, away_goals)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	if _, err := tx.Exec(query, CurrentSeason, match.Week, match.HomeTeam, match.AwayTeam, match.HomeGoals, match.AwayGoals); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 func SaveHistoricalMatch(match models.Match) error {
 	query := `
         INSERT INTO historical_matches 
         (season, week, home_team_name, away_team_name, home_goals, away_goals)
-        VALUES (1, ?, ?, ?, ?, ?)
+        VALUES (?, ?, ?, ?, ?, ?)
     `
 	_, err := DB.Exec(query,
+		CurrentSeason,
 		match.Week,
 		match.HomeTeam,
 		match.AwayTeam,
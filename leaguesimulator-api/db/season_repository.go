@@ -0,0 +1,96 @@
+package db
+
+import (
+	"leaguesimulator/models"
+)
+
+// ArchiveSeason stores the final table of a season and replaces its
+// historical matches with the given played matches, in one transaction
+func ArchiveSeason(season int, standings []models.SeasonStanding, matches []models.Match) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM season_standings WHERE season = ?`, season); err != nil {
+		return err
+	}
+	standingQuery := `
+		INSERT INTO season_standings
+		(season, position, team_name, played, won, drawn, lost, goals_for, goals_against, points)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	for _, standing := range standings {
+		_, err := tx.Exec(standingQuery,
+			season,
+			standing.Position,
+			standing.Team,
+			standing.Played,
+			standing.Won,
+			standing.Drawn,
+			standing.Lost,
+			standing.GoalsFor,
+			standing.GoalsAgainst,
+			standing.Points,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM historical_matches WHERE season = ?`, season); err != nil {
+		return err
+	}
+	historicalQuery := `
+		INSERT INTO historical_matches (season, week, home_team_name, away_team_name, home_goals, away_goals)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	for _, match := range matches {
+		if !match.Played {
+			continue
+		}
+		_, err := tx.Exec(historicalQuery, season, match.Week, match.HomeTeam, match.AwayTeam, match.HomeGoals, match.AwayGoals)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetAllSeasonStandings returns every archived table row ordered by season and position
+func GetAllSeasonStandings() ([]models.SeasonStanding, error) {
+	query := `
+		SELECT season, position, team_name, played, won, drawn, lost, goals_for, goals_against, points
+		FROM season_standings
+		ORDER BY season, position
+	`
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	standings := []models.SeasonStanding{}
+	for rows.Next() {
+		var standing models.SeasonStanding
+		err := rows.Scan(
+			&standing.Season,
+			&standing.Position,
+			&standing.Team,
+			&standing.Played,
+			&standing.Won,
+			&standing.Drawn,
+			&standing.Lost,
+			&standing.GoalsFor,
+			&standing.GoalsAgainst,
+			&standing.Points,
+		)
+		if err != nil {
+			return nil, err
+		}
+		standings = append(standings, standing)
+	}
+	return standings, rows.Err()
+}
@@ -0,0 +1,31 @@
+package db
+
+import "database/sql"
+
+// GetLeagueSetting returns a stored league setting and whether it was found
+func GetLeagueSetting(name string) (string, bool, error) {
+	var value string
+	err := DB.QueryRow(`SELECT value FROM league_settings WHERE name = ?`, name).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+func SaveLeagueSetting(name string, value string) error {
+	query := `
+		INSERT INTO league_settings (name, value)
+		VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE value = VALUES(value)
+	`
+	_, err := DB.Exec(query, name, value)
+	return err
+}
+
+func DeleteLeagueSetting(name string) error {
+	_, err := DB.Exec(`DELETE FROM league_settings WHERE name = ?`, name)
+	return err
+}
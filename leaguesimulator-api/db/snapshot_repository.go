@@ -0,0 +1,293 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+
+	"leaguesimulator/models"
+)
+
+func CreateSnapshot(snapshot models.Snapshot) (int, error) {
+	query := `INSERT INTO snapshots (name, version, week, document) VALUES (?, ?, ?, ?)`
+	result, err := DB.Exec(query, snapshot.Name, snapshot.Version, snapshot.Week, string(snapshot.Document))
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+// GetAllSnapshots lists saved snapshots without their documents
+func GetAllSnapshots() ([]models.Snapshot, error) {
+	rows, err := DB.Query(`SELECT id, name, version, week, created_at FROM snapshots ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := []models.Snapshot{}
+	for rows.Next() {
+		var snapshot models.Snapshot
+		err := rows.Scan(
+			&snapshot.ID,
+			&snapshot.Name,
+			&snapshot.Version,
+			&snapshot.Week,
+			&snapshot.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// GetSnapshot loads a snapshot with its document. It returns sql.ErrNoRows
+// when the snapshot does not exist.
+func GetSnapshot(snapshotID int) (*models.Snapshot, error) {
+	var snapshot models.Snapshot
+	var document string
+	query := `SELECT id, name, version, week, created_at, document FROM snapshots WHERE id = ?`
+	err := DB.QueryRow(query, snapshotID).Scan(
+		&snapshot.ID,
+		&snapshot.Name,
+		&snapshot.Version,
+		&snapshot.Week,
+		&snapshot.CreatedAt,
+		&document,
+	)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Document = []byte(document)
+	return &snapshot, nil
+}
+
+// DeleteSnapshot removes a snapshot. It returns sql.ErrNoRows when the snapshot does not exist.
+func DeleteSnapshot(snapshotID int) error {
+	result, err := DB.Exec(`DELETE FROM snapshots WHERE id = ?`, snapshotID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+// RestoreLeagueState replaces the teams, matches with their scorers and
+// result edits, and the given league settings in one transaction. Deleting
+// the old matches also deletes their result edits, so edits to keep must be
+// passed in again. The played matches become the historical matches of
+// season, replacing those of season and of the running season. Teams that
+// are not listed are removed. A setting with an empty value is deleted.
+func RestoreLeagueState(season int, teams []models.Team, matches []models.Match, scorers [][]models.Goal, edits [][]models.MatchEdit, settings map[string]string) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM matches`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM historical_matches WHERE season IN (?, ?)`, CurrentSeason, season); err != nil {
+		return err
+	}
+
+	if len(teams) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(teams)), ", ")
+		names := make([]interface{}, len(teams))
+		for i, team := range teams {
+			names[i] = team.Name
+		}
+		if _, err := tx.Exec(`DELETE FROM teams WHERE name NOT IN (`+placeholders+`)`, names...); err != nil {
+			return err
+		}
+	}
+
+	teamQuery := `
+		INSERT INTO teams
+		(name, points, played, wins, draws, losses, goals_for, goals_against, strength, venue_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+		points=VALUES(points),
+		played=VALUES(played),
+		wins=VALUES(wins),
+		draws=VALUES(draws),
+		losses=VALUES(losses),
+		goals_for=VALUES(goals_for),
+		goals_against=VALUES(goals_against),
+		strength=VALUES(strength),
+		venue_id=VALUES(venue_id)
+	`
+	for _, team := range teams {
+		_, err := tx.Exec(teamQuery,
+			team.Name,
+			team.Points,
+			team.Played,
+			team.Wins,
+			team.Draws,
+			team.Losses,
+			team.GoalsFor,
+			team.GoalsAgainst,
+			team.Strength,
+			nullInt(team.VenueID),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	matchQuery := `
+		INSERT INTO matches
+		(week, home_team_name, away_team_name, home_goals, away_goals, played, kick_off, venue_id, postponed, postponement_reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	goalQuery := `
+		INSERT INTO match_goals (match_id, team_name, player_name, minute, own_goal, penalty)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	editQuery := `
+		INSERT INTO match_result_edits
+		(match_id, old_home_goals, old_away_goals, new_home_goals, new_away_goals, reason, actor, revert_of, edited_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	historicalQuery := `
+		INSERT INTO historical_matches (season, week, home_team_name, away_team_name, home_goals, away_goals)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	// Edits get new IDs, so reverts are pointed at the new ID of the edit they undo
+	editIDs := make(map[int]int)
+	for i, match := range matches {
+		result, err := tx.Exec(matchQuery,
+			match.Week,
+			match.HomeTeam,
+			match.AwayTeam,
+			match.HomeGoals,
+			match.AwayGoals,
+			match.Played,
+			match.KickOff,
+			nullInt(match.VenueID),
+			match.Postponed,
+			nullString(match.PostponementReason),
+		)
+		if err != nil {
+			return err
+		}
+		matchID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		if i < len(scorers) {
+			for _, goal := range scorers[i] {
+				_, err := tx.Exec(goalQuery, matchID, goal.Team, goal.Player, nullInt(goal.Minute), goal.OwnGoal, goal.Penalty)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if i < len(edits) {
+			for _, edit := range edits[i] {
+				result, err := tx.Exec(editQuery,
+					matchID,
+					edit.OldHomeGoals,
+					edit.OldAwayGoals,
+					edit.NewHomeGoals,
+					edit.NewAwayGoals,
+					edit.Reason,
+					edit.Actor,
+					nullInt(editIDs[edit.RevertOf]),
+					edit.EditedAt,
+				)
+				if err != nil {
+					return err
+				}
+				editID, err := result.LastInsertId()
+				if err != nil {
+					return err
+				}
+				editIDs[edit.ID] = int(editID)
+			}
+		}
+
+		if match.Played {
+			_, err := tx.Exec(historicalQuery, season, match.Week, match.HomeTeam, match.AwayTeam, match.HomeGoals, match.AwayGoals)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for name, value := range settings {
+		if value == "" {
+			if _, err := tx.Exec(`DELETE FROM league_settings WHERE name = ?`, name); err != nil {
+				return err
+			}
+			continue
+		}
+		query := `
+			INSERT INTO league_settings (name, value)
+			VALUES (?, ?)
+			ON DUPLICATE KEY UPDATE value = VALUES(value)
+		`
+		if _, err := tx.Exec(query, name, value); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// teamHistoryColumns are the team columns whose rows are deleted along with
+// a team, apart from the current season's matches that a restore replaces
+var teamHistoryColumns = []struct {
+	table   string
+	columns []string
+}{
+	{"historical_matches", []string{"home_team_name", "away_team_name"}},
+	{"predictions", []string{"team_name"}},
+	{"team_aliases", []string{"team_name"}},
+	{"cup_entrants", []string{"team_name"}},
+	{"tournament_entrants", []string{"team_name"}},
+	{"division_members", []string{"team_name"}},
+}
+
+// CountTeamHistory returns, per table, how many rows removing the given teams
+// would delete through their foreign keys. Tables without rows are left out.
+func CountTeamHistory(names []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(names) == 0 {
+		return counts, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")
+	for _, source := range teamHistoryColumns {
+		var conditions []string
+		var args []interface{}
+		for _, column := range source.columns {
+			conditions = append(conditions, column+" IN ("+placeholders+")")
+			for _, name := range names {
+				args = append(args, name)
+			}
+		}
+		query := "SELECT COUNT(*) FROM " + source.table + " WHERE (" + strings.Join(conditions, " OR ") + ")"
+		if source.table == "historical_matches" {
+			query += " AND season <> ?"
+			args = append(args, CurrentSeason)
+		}
+
+		var count int
+		if err := DB.QueryRow(query, args...).Scan(&count); err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			counts[source.table] = count
+		}
+	}
+	return counts, nil
+}
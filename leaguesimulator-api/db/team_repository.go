@@ -5,7 +5,7 @@ import (
 )
 
 func GetAllTeams() ([]models.Team, error) {
-	query := `SELECT name, points, played, wins, draws, losses, goals_for, goals_against, strength FROM teams ORDER BY name`
+	query := `SELECT name, points, played, wins, draws, losses, goals_for, goals_against, strength, COALESCE(venue_id, 0) FROM teams ORDER BY name`
 	rows, err := DB.Query(query)
 	if err != nil {
 		return nil, err
@@ -25,6 +25,7 @@ func GetAllTeams() ([]models.Team, error) {
 			&team.GoalsFor,
 			&team.GoalsAgainst,
 			&team.Strength,
+			&team.VenueID,
 		)
 		if err != nil {
 			return nil, err
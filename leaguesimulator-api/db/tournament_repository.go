@@ -0,0 +1,293 @@
+package db
+
+import (
+	"database/sql"
+
+	"leaguesimulator/models"
+)
+
+// CreateTournament stores a tournament with its entrants in one transaction
+func CreateTournament(tournament models.Tournament) (int, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO tournaments
+		(name, group_count, qualifiers_per_group, extra_qualifiers, knockout_legs, status, current_matchday)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := tx.Exec(query,
+		tournament.Name,
+		tournament.GroupCount,
+		tournament.QualifiersPerGroup,
+		tournament.ExtraQualifiers,
+		tournament.KnockoutLegs,
+		tournament.Status,
+		tournament.CurrentMatchday,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for seed, teamName := range tournament.Entrants {
+		_, err := tx.Exec(`INSERT INTO tournament_entrants (tournament_id, team_name, seed) VALUES (?, ?, ?)`,
+			id, teamName, seed+1)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return int(id), tx.Commit()
+}
+
+func GetAllTournaments() ([]models.Tournament, error) {
+	query := `
+		SELECT id, name, group_count, qualifiers_per_group, extra_qualifiers, knockout_legs,
+		       status, current_matchday, cup_id, champion_name
+		FROM tournaments ORDER BY id
+	`
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tournaments []models.Tournament
+	for rows.Next() {
+		tournament, err := scanTournament(rows)
+		if err != nil {
+			return nil, err
+		}
+		tournaments = append(tournaments, tournament)
+	}
+	return tournaments, nil
+}
+
+// GetTournament loads a tournament with its entrants, groups and group matches.
+// It returns sql.ErrNoRows when the tournament does not exist.
+func GetTournament(tournamentID int) (*models.Tournament, error) {
+	query := `
+		SELECT id, name, group_count, qualifiers_per_group, extra_qualifiers, knockout_legs,
+		       status, current_matchday, cup_id, champion_name
+		FROM tournaments WHERE id = ?
+	`
+	tournament, err := scanTournament(DB.QueryRow(query, tournamentID))
+	if err != nil {
+		return nil, err
+	}
+
+	entrantRows, err := DB.Query(`SELECT team_name FROM tournament_entrants WHERE tournament_id = ? ORDER BY seed`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer entrantRows.Close()
+
+	for entrantRows.Next() {
+		var teamName string
+		if err := entrantRows.Scan(&teamName); err != nil {
+			return nil, err
+		}
+		tournament.Entrants = append(tournament.Entrants, teamName)
+	}
+
+	groupRows, err := DB.Query(`SELECT group_name, team_name FROM tournament_groups WHERE tournament_id = ? ORDER BY group_name, pot`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer groupRows.Close()
+
+	tournament.Groups = []models.TournamentGroup{}
+	for groupRows.Next() {
+		var groupName, teamName string
+		if err := groupRows.Scan(&groupName, &teamName); err != nil {
+			return nil, err
+		}
+		last := len(tournament.Groups) - 1
+		if last < 0 || tournament.Groups[last].Name != groupName {
+			tournament.Groups = append(tournament.Groups, models.TournamentGroup{Name: groupName})
+			last++
+		}
+		tournament.Groups[last].Teams = append(tournament.Groups[last].Teams, teamName)
+	}
+
+	matches, err := GetTournamentMatches(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	tournament.Matches = matches
+
+	return &tournament, nil
+}
+
+func GetTournamentMatches(tournamentID int) ([]models.TournamentMatch, error) {
+	query := `
+		SELECT id, tournament_id, group_name, matchday, home_team_name, away_team_name, home_goals, away_goals, played
+		FROM tournament_matches
+		WHERE tournament_id = ?
+		ORDER BY matchday, group_name, id
+	`
+	rows, err := DB.Query(query, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []models.TournamentMatch{}
+	for rows.Next() {
+		var match models.TournamentMatch
+		err := rows.Scan(
+			&match.ID,
+			&match.TournamentID,
+			&match.Group,
+			&match.Matchday,
+			&match.HomeTeam,
+			&match.AwayTeam,
+			&match.HomeGoals,
+			&match.AwayGoals,
+			&match.Played,
+		)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// SaveTournamentDraw stores the drawn groups, the group matches and the
+// tournament's new status in one transaction, filling in the match IDs
+func SaveTournamentDraw(tournament *models.Tournament) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	groupQuery := `INSERT INTO tournament_groups (tournament_id, group_name, team_name, pot) VALUES (?, ?, ?, ?)`
+	for _, group := range tournament.Groups {
+		for pot, teamName := range group.Teams {
+			_, err := tx.Exec(groupQuery, tournament.ID, group.Name, teamName, pot+1)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	matchQuery := `
+		INSERT INTO tournament_matches
+		(tournament_id, group_name, matchday, home_team_name, away_team_name, home_goals, away_goals, played)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	for i := range tournament.Matches {
+		match := &tournament.Matches[i]
+		result, err := tx.Exec(matchQuery,
+			tournament.ID,
+			match.Group,
+			match.Matchday,
+			match.HomeTeam,
+			match.AwayTeam,
+			match.HomeGoals,
+			match.AwayGoals,
+			match.Played,
+		)
+		if err != nil {
+			return err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		match.ID = int(id)
+	}
+
+	_, err = tx.Exec(`UPDATE tournaments SET status = ?, current_matchday = ? WHERE id = ?`,
+		tournament.Status, tournament.CurrentMatchday, tournament.ID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SaveTournamentRound stores a played tournament round in one transaction:
+// the results of group matchday, if it is not zero, the knockout cup with the
+// results of its playedRound and its newly drawn ties, if cup is not nil, and
+// the tournament's progress. A knockout cup without an ID is created first
+// and linked to the tournament.
+func SaveTournamentRound(tournament *models.Tournament, matchday int, cup *models.Cup, playedRound int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if matchday != 0 {
+		query := `UPDATE tournament_matches SET home_goals = ?, away_goals = ?, played = ? WHERE id = ?`
+		for _, match := range tournament.Matches {
+			if match.Matchday != matchday {
+				continue
+			}
+			if _, err := tx.Exec(query, match.HomeGoals, match.AwayGoals, match.Played, match.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cup != nil {
+		if cup.ID == 0 {
+			if err := insertCup(tx, cup); err != nil {
+				return err
+			}
+		}
+		if err := saveCupRound(tx, cup, playedRound); err != nil {
+			return err
+		}
+		tournament.CupID = cup.ID
+	}
+
+	query := `
+		UPDATE tournaments
+		SET status = ?, current_matchday = ?, cup_id = ?, champion_name = ?
+		WHERE id = ?
+	`
+	_, err = tx.Exec(query,
+		tournament.Status,
+		tournament.CurrentMatchday,
+		nullInt(tournament.CupID),
+		nullString(tournament.Champion),
+		tournament.ID,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func scanTournament(row rowScanner) (models.Tournament, error) {
+	var tournament models.Tournament
+	var cupID sql.NullInt64
+	var champion sql.NullString
+	err := row.Scan(
+		&tournament.ID,
+		&tournament.Name,
+		&tournament.GroupCount,
+		&tournament.QualifiersPerGroup,
+		&tournament.ExtraQualifiers,
+		&tournament.KnockoutLegs,
+		&tournament.Status,
+		&tournament.CurrentMatchday,
+		&cupID,
+		&champion,
+	)
+	tournament.CupID = int(cupID.Int64)
+	tournament.Champion = champion.String
+	return tournament, err
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"database/sql"
+
+	"leaguesimulator/models"
+)
+
+func CreateVenue(venue models.Venue) (int, error) {
+	query := `INSERT INTO venues (name, city, capacity) VALUES (?, ?, ?)`
+
+	result, err := DB.Exec(query, venue.Name, venue.City, venue.Capacity)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+// GetAllVenues returns every venue with the teams that use it as their home ground
+func GetAllVenues() ([]models.Venue, error) {
+	query := `
+		SELECT v.id, v.name, v.city, v.capacity, t.name
+		FROM venues v
+		LEFT JOIN teams t ON t.venue_id = v.id
+		ORDER BY v.name, t.name
+	`
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var venues []models.Venue
+	for rows.Next() {
+		var venue models.Venue
+		var teamName sql.NullString
+		if err := rows.Scan(&venue.ID, &venue.Name, &venue.City, &venue.Capacity, &teamName); err != nil {
+			return nil, err
+		}
+
+		last := len(venues) - 1
+		if last < 0 || venues[last].ID != venue.ID {
+			venue.Teams = []string{}
+			venues = append(venues, venue)
+			last++
+		}
+		if teamName.Valid {
+			venues[last].Teams = append(venues[last].Teams, teamName.String)
+		}
+	}
+	return venues, nil
+}
+
+// AssignTeamVenue sets the home ground of a team; venueID 0 clears it
+func AssignTeamVenue(teamName string, venueID int) error {
+	_, err := DB.Exec(`UPDATE teams SET venue_id = ? WHERE name = ?`, nullInt(venueID), teamName)
+	return err
+}
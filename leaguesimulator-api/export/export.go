@@ -0,0 +1,132 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+	FormatExcel = "excel"
+
+	// excelMediaType is the Accept value that asks for the Excel-friendly variant
+	excelMediaType = "application/vnd.ms-excel"
+)
+
+// ErrInvalidFormat is returned for an export format that is not supported
+var ErrInvalidFormat = errors.New("invalid export format")
+
+// Table is one sheet of exported data: a header row and the rows below it.
+// Name is used as the file name inside a bundle.
+type Table struct {
+	Name   string
+	Header []string
+	Rows   [][]string
+}
+
+// Negotiate picks the export format from an explicit ?format= value, falling
+// back to the Accept header and then to JSON
+func Negotiate(format, accept string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatExcel, "xls":
+		return FormatExcel, nil
+	case "":
+	default:
+		return "", fmt.Errorf("%w: format must be %q, %q or %q", ErrInvalidFormat, FormatJSON, FormatCSV, FormatExcel)
+	}
+
+	accept = strings.ToLower(accept)
+	switch {
+	case strings.Contains(accept, excelMediaType):
+		return FormatExcel, nil
+	case strings.Contains(accept, "text/csv"):
+		return FormatCSV, nil
+	}
+	return FormatJSON, nil
+}
+
+// ContentType is the media type of a table written in the given format
+func ContentType(format string) string {
+	if format == FormatExcel {
+		return excelMediaType + "; charset=utf-8"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// WriteCSV writes a table as CSV. Text that a spreadsheet would otherwise run
+// as a formula is quoted in both variants, since plain CSV files are opened in
+// spreadsheets too. The Excel variant also starts with a UTF-8 byte order mark
+// and ends lines with CRLF, so team names with accents survive.
+func WriteCSV(w io.Writer, table Table, format string) error {
+	excel := format == FormatExcel
+	if excel {
+		if _, err := io.WriteString(w, "\ufeff"); err != nil {
+			return err
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = excel
+	for _, row := range append([][]string{table.Header}, table.Rows...) {
+		if err := writer.Write(escapeFormulas(row)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteZip writes every table as <name>.csv into one zip archive
+func WriteZip(w io.Writer, tables []Table, format string) error {
+	archive := zip.NewWriter(w)
+	for _, table := range tables {
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     table.Name + ".csv",
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		if err := WriteCSV(file, table, format); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// Time formats an optional time for a cell, empty when it is not set
+func Time(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// escapeFormulas prefixes text cells that start like a formula, or with a tab
+// or carriage return, with a quote. Plain numbers such as a negative goal
+// difference are left alone.
+func escapeFormulas(row []string) []string {
+	escaped := make([]string, len(row))
+	for i, cell := range row {
+		escaped[i] = cell
+		if cell == "" || !strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			continue
+		}
+		if _, err := strconv.ParseFloat(cell, 64); err != nil {
+			escaped[i] = "'" + cell
+		}
+	}
+	return escaped
+}
@@ -0,0 +1,27 @@
+package export
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEscapeFormulas(t *testing.T) {
+	tests := []struct {
+		name string
+		row  []string
+		want []string
+	}{
+		{name: "plain text and numbers", row: []string{"Lions", "12", ""}, want: []string{"Lions", "12", ""}},
+		{name: "negative and signed numbers", row: []string{"-3", "+2", "-0.5"}, want: []string{"-3", "+2", "-0.5"}},
+		{name: "formulas", row: []string{"=1+1", "@SUM(A1)", "+cmd", "-cmd"}, want: []string{"'=1+1", "'@SUM(A1)", "'+cmd", "'-cmd"}},
+		{name: "tab and carriage return", row: []string{"\t=1+1", "\rLions"}, want: []string{"'\t=1+1", "'\rLions"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := escapeFormulas(test.row); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("escapeFormulas(%q) = %q, want %q", test.row, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package ical
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"leaguesimulator/models"
+)
+
+// matchDuration is how long a calendar event for a match lasts
+const matchDuration = 2 * time.Hour
+
+// BuildCalendar renders the given fixtures as an iCalendar (RFC 5545)
+// document. Fixtures without a kick-off time are left out.
+func BuildCalendar(calendarName string, matches []models.Match) string {
+	var b strings.Builder
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:-//League Simulator//Fixtures//EN")
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	writeLine(&b, "X-WR-CALNAME:"+escapeText(calendarName))
+
+	for _, match := range matches {
+		if match.KickOff == nil {
+			continue
+		}
+
+		start := match.KickOff.UTC()
+		summary := fmt.Sprintf("%s vs %s", match.HomeTeam, match.AwayTeam)
+		if match.Played {
+			summary = fmt.Sprintf("%s %d-%d %s", match.HomeTeam, match.HomeGoals, match.AwayGoals, match.AwayTeam)
+		}
+
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, fmt.Sprintf("UID:match-%d@leaguesimulator", match.ID))
+		writeLine(&b, "DTSTAMP:"+stamp)
+		writeLine(&b, "DTSTART:"+start.Format("20060102T150405Z"))
+		writeLine(&b, "DTEND:"+start.Add(matchDuration).Format("20060102T150405Z"))
+		writeLine(&b, "SUMMARY:"+escapeText(summary))
+		writeLine(&b, "DESCRIPTION:"+escapeText(fmt.Sprintf("Week %d", match.Week)))
+		if match.Venue != "" {
+			writeLine(&b, "LOCATION:"+escapeText(match.Venue))
+		}
+		writeLine(&b, "END:VEVENT")
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+// escapeText escapes the characters that are special in iCalendar TEXT values
+func escapeText(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	return replacer.Replace(value)
+}
+
+// writeLine writes a content line with CRLF ending, folding it so that no
+// line is longer than 75 octets. Continuation lines start with a space, which
+// counts towards their 75 octets.
+func writeLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		// Never split a multi-byte UTF-8 character
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,54 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWriteLineFolding(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		lines int
+	}{
+		{name: "short line", line: "SUMMARY:Lions vs Tigers", lines: 1},
+		{name: "exactly 75 octets", line: strings.Repeat("a", 75), lines: 1},
+		{name: "76 octets", line: strings.Repeat("a", 76), lines: 2},
+		{name: "fills two continuation lines", line: strings.Repeat("a", 75+74+74), lines: 3},
+		{name: "one octet over two continuation lines", line: strings.Repeat("a", 75+74+74+1), lines: 4},
+		{name: "two-byte characters", line: "LOCATION:" + strings.Repeat("é", 100), lines: 3},
+		{name: "three-byte characters", line: "SUMMARY:" + strings.Repeat("€", 60), lines: 3},
+		{name: "character across the fold", line: strings.Repeat("a", 74) + "€" + strings.Repeat("b", 10), lines: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b strings.Builder
+			writeLine(&b, test.line)
+			output := b.String()
+
+			if !strings.HasSuffix(output, "\r\n") {
+				t.Fatalf("output %q does not end with CRLF", output)
+			}
+			lines := strings.Split(strings.TrimSuffix(output, "\r\n"), "\r\n")
+			if len(lines) != test.lines {
+				t.Errorf("got %d lines, want %d", len(lines), test.lines)
+			}
+			for i, line := range lines {
+				if len(line) > 75 {
+					t.Errorf("line %d is %d octets long", i+1, len(line))
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space", i+1)
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a UTF-8 character: %q", i+1, line)
+				}
+			}
+			if unfolded := strings.ReplaceAll(strings.TrimSuffix(output, "\r\n"), "\r\n ", ""); unfolded != test.line {
+				t.Errorf("unfolded line = %q, want %q", unfolded, test.line)
+			}
+		})
+	}
+}
@@ -0,0 +1,402 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"leaguesimulator/models"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+
+	minStrength = 1
+	maxStrength = 100
+)
+
+// ErrInvalidImport is returned when an import file cannot be read or has invalid rows
+var ErrInvalidImport = errors.New("invalid import")
+
+// RowError is a problem with one row of an import file. Row counts data rows
+// from 1; for CSV files Line is the line number including the header.
+type RowError struct {
+	File    string `json:"file"`
+	Row     int    `json:"row"`
+	Line    int    `json:"line,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects every row error of an import
+type ValidationError struct {
+	Rows []RowError
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v: %d row error(s)", ErrInvalidImport, len(e.Rows))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidImport
+}
+
+// DetectFormat picks csv or json from an explicit format, a file name or a content type
+func DetectFormat(format, fileName, contentType string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(fileName)) {
+		case ".csv":
+			format = FormatCSV
+		case ".json":
+			format = FormatJSON
+		}
+	}
+	if format == "" {
+		switch {
+		case strings.Contains(contentType, "csv"):
+			format = FormatCSV
+		case strings.Contains(contentType, "json"):
+			format = FormatJSON
+		}
+	}
+	if format != FormatCSV && format != FormatJSON {
+		return "", fmt.Errorf("%w: format must be %q or %q", ErrInvalidImport, FormatCSV, FormatJSON)
+	}
+	return format, nil
+}
+
+// teamRow is one team as written in an import file. Strength can be given
+// directly, as rating, or as attack and defence ratings that are averaged.
+type teamRow struct {
+	Name     string   `json:"name"`
+	Strength *float64 `json:"strength"`
+	Rating   *float64 `json:"rating"`
+	Attack   *float64 `json:"attack"`
+	Defence  *float64 `json:"defence"`
+	Defense  *float64 `json:"defense"`
+}
+
+// fixtureRow is one fixture as written in an import file. Goals are left
+// empty for a fixture that has not been played.
+type fixtureRow struct {
+	Week      int    `json:"week"`
+	HomeTeam  string `json:"home_team"`
+	AwayTeam  string `json:"away_team"`
+	HomeGoals *int   `json:"home_goals"`
+	AwayGoals *int   `json:"away_goals"`
+	KickOff   string `json:"kick_off"`
+}
+
+// ParseTeams reads teams from a CSV file with a header row (name plus
+// strength, rating or attack and defence) or from a JSON array of objects
+// with the same fields
+func ParseTeams(r io.Reader, format string) ([]models.Team, error) {
+	const file = "teams"
+	var rows []teamRow
+	var lines []int
+	var problems []RowError
+	broken := make(map[int]bool)
+
+	switch format {
+	case FormatJSON:
+		if err := json.NewDecoder(r).Decode(&rows); err != nil {
+			return nil, fmt.Errorf("%w: teams: %v", ErrInvalidImport, err)
+		}
+	default:
+		records, header, err := readCSV(r, file, "name")
+		if err != nil {
+			return nil, err
+		}
+		for i, record := range records {
+			row := teamRow{Name: field(record, header, "name")}
+			for _, column := range []struct {
+				name   string
+				target **float64
+			}{
+				{"strength", &row.Strength},
+				{"rating", &row.Rating},
+				{"attack", &row.Attack},
+				{"defence", &row.Defence},
+				{"defense", &row.Defense},
+			} {
+				name, target := column.name, column.target
+				value := field(record, header, name)
+				if value == "" {
+					continue
+				}
+				number, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					problems = append(problems, RowError{File: file, Row: i + 1, Line: i + 2, Field: name, Message: fmt.Sprintf("%q is not a number", value)})
+					broken[i] = true
+					continue
+				}
+				*target = &number
+			}
+			rows = append(rows, row)
+			lines = append(lines, i+2)
+		}
+	}
+
+	teams := []models.Team{}
+	seen := make(map[string]int)
+	for i, row := range rows {
+		problem := func(field, message string) {
+			rowError := RowError{File: file, Row: i + 1, Field: field, Message: message}
+			if i < len(lines) {
+				rowError.Line = lines[i]
+			}
+			problems = append(problems, rowError)
+		}
+		if broken[i] {
+			continue
+		}
+
+		name := strings.TrimSpace(row.Name)
+		switch {
+		case name == "":
+			problem("name", "a team name is required")
+			continue
+		case len(name) > 100:
+			problem("name", "the team name is longer than 100 characters")
+			continue
+		case seen[name] != 0:
+			problem("name", fmt.Sprintf("%s is already listed in row %d", name, seen[name]))
+			continue
+		}
+		seen[name] = i + 1
+
+		defence := row.Defence
+		if defence == nil {
+			defence = row.Defense
+		}
+		var strength float64
+		switch {
+		case row.Strength != nil:
+			strength = *row.Strength
+		case row.Rating != nil:
+			strength = *row.Rating
+		case row.Attack != nil && defence != nil:
+			strength = (*row.Attack + *defence) / 2
+		default:
+			problem("strength", "a strength, a rating or both attack and defence are required")
+			continue
+		}
+		if strength < minStrength || strength > maxStrength {
+			problem("strength", fmt.Sprintf("strength %g is outside %d-%d", strength, minStrength, maxStrength))
+			continue
+		}
+
+		teams = append(teams, models.Team{Name: name, Strength: int(math.Round(strength))})
+	}
+
+	if len(problems) == 0 && len(teams) < 2 {
+		problems = append(problems, RowError{File: file, Message: "at least two teams are required"})
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Rows: problems}
+	}
+	return teams, nil
+}
+
+// ParseFixtures reads fixtures from a CSV file with a header row (week,
+// home_team, away_team, home_goals, away_goals and an optional kick_off) or
+// from a JSON array of objects with the same fields. Every team must be one
+// of the given teams and no team may play twice in a week.
+func ParseFixtures(r io.Reader, format string, teams []models.Team) ([]models.Match, error) {
+	const file = "fixtures"
+	var rows []fixtureRow
+	var lines []int
+	var problems []RowError
+	broken := make(map[int]bool)
+
+	switch format {
+	case FormatJSON:
+		if err := json.NewDecoder(r).Decode(&rows); err != nil {
+			return nil, fmt.Errorf("%w: fixtures: %v", ErrInvalidImport, err)
+		}
+	default:
+		records, header, err := readCSV(r, file, "week", "home_team", "away_team")
+		if err != nil {
+			return nil, err
+		}
+		for i, record := range records {
+			line := i + 2
+			row := fixtureRow{
+				HomeTeam: field(record, header, "home_team"),
+				AwayTeam: field(record, header, "away_team"),
+				KickOff:  field(record, header, "kick_off"),
+			}
+			week, err := strconv.Atoi(field(record, header, "week"))
+			if err != nil {
+				problems = append(problems, RowError{File: file, Row: i + 1, Line: line, Field: "week", Message: "week must be a number"})
+				broken[i] = true
+			}
+			row.Week = week
+			for _, column := range []struct {
+				name   string
+				target **int
+			}{
+				{"home_goals", &row.HomeGoals},
+				{"away_goals", &row.AwayGoals},
+			} {
+				name, target := column.name, column.target
+				value := field(record, header, name)
+				if value == "" {
+					continue
+				}
+				goals, err := strconv.Atoi(value)
+				if err != nil {
+					problems = append(problems, RowError{File: file, Row: i + 1, Line: line, Field: name, Message: fmt.Sprintf("%q is not a number", value)})
+					broken[i] = true
+					continue
+				}
+				*target = &goals
+			}
+			rows = append(rows, row)
+			lines = append(lines, line)
+		}
+	}
+
+	known := make(map[string]bool)
+	for _, team := range teams {
+		known[team.Name] = true
+	}
+
+	matches := []models.Match{}
+	busy := make(map[string]int)
+	for i, row := range rows {
+		problem := func(field, message string) {
+			rowError := RowError{File: file, Row: i + 1, Field: field, Message: message}
+			if i < len(lines) {
+				rowError.Line = lines[i]
+			}
+			problems = append(problems, rowError)
+		}
+		if broken[i] {
+			continue
+		}
+
+		home := strings.TrimSpace(row.HomeTeam)
+		away := strings.TrimSpace(row.AwayTeam)
+		valid := true
+		if row.Week < 1 {
+			problem("week", "week must be 1 or later")
+			valid = false
+		}
+		if !known[home] {
+			problem("home_team", fmt.Sprintf("%q is not one of the imported teams", home))
+			valid = false
+		}
+		if !known[away] {
+			problem("away_team", fmt.Sprintf("%q is not one of the imported teams", away))
+			valid = false
+		}
+		if home != "" && home == away {
+			problem("away_team", "a team cannot play itself")
+			valid = false
+		}
+		if (row.HomeGoals == nil) != (row.AwayGoals == nil) {
+			problem("away_goals", "give both scores for a played match or neither for a fixture")
+			valid = false
+		}
+		if (row.HomeGoals != nil && *row.HomeGoals < 0) || (row.AwayGoals != nil && *row.AwayGoals < 0) {
+			problem("home_goals", "goals cannot be negative")
+			valid = false
+		}
+
+		var kickOff *time.Time
+		if row.KickOff != "" {
+			parsed, err := parseKickOff(row.KickOff)
+			if err != nil {
+				problem("kick_off", err.Error())
+				valid = false
+			}
+			kickOff = parsed
+		}
+
+		if !valid {
+			continue
+		}
+		for _, team := range []string{home, away} {
+			key := fmt.Sprintf("%d|%s", row.Week, team)
+			if other := busy[key]; other != 0 {
+				problem("week", fmt.Sprintf("%s already plays in week %d (row %d)", team, row.Week, other))
+				valid = false
+			}
+			busy[key] = i + 1
+		}
+		if !valid {
+			continue
+		}
+
+		match := models.Match{Week: row.Week, HomeTeam: home, AwayTeam: away, KickOff: kickOff}
+		if row.HomeGoals != nil {
+			match.HomeGoals = *row.HomeGoals
+			match.AwayGoals = *row.AwayGoals
+			match.Played = true
+		}
+		matches = append(matches, match)
+	}
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Rows: problems}
+	}
+	return matches, nil
+}
+
+// parseKickOff accepts RFC 3339 times and plain "2006-01-02 15:04" or "2006-01-02" in UTC
+func parseKickOff(value string) (*time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			parsed = parsed.UTC()
+			return &parsed, nil
+		}
+	}
+	return nil, fmt.Errorf("%q is not a date or time", value)
+}
+
+// readCSV reads every record of a CSV file and maps its lower-cased header
+// names to column indexes, checking that the required columns are present
+func readCSV(r io.Reader, file string, required ...string) ([][]string, map[string]int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s: %v", ErrInvalidImport, file, err)
+	}
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("%w: %s: the file is empty", ErrInvalidImport, file)
+	}
+
+	header := make(map[string]int)
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		header[name] = i
+	}
+	for _, name := range required {
+		if _, ok := header[name]; !ok {
+			return nil, nil, fmt.Errorf("%w: %s: the header has no %q column", ErrInvalidImport, file, name)
+		}
+	}
+	return records[1:], header, nil
+}
+
+// field returns a trimmed column of a record, or "" when the column is missing
+func field(record []string, header map[string]int, name string) string {
+	i, ok := header[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
@@ -0,0 +1,150 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// seasonStartMonth is the first month of a season in football-data files;
+// matches before it belong to the season that started the year before
+const seasonStartMonth = time.July
+
+// ResultRow is one played match from a football-data style results file.
+// SeasonYear is the calendar year in which its season started.
+type ResultRow struct {
+	Row        int       `json:"row"`
+	Line       int       `json:"line"`
+	Date       time.Time `json:"date"`
+	SeasonYear int       `json:"season_year"`
+	HomeTeam   string    `json:"home_team"`
+	AwayTeam   string    `json:"away_team"`
+	HomeGoals  int       `json:"home_goals"`
+	AwayGoals  int       `json:"away_goals"`
+}
+
+// ParseResults reads a results CSV in the layout of football-data.co.uk and
+// similar open data sets: Date, HomeTeam, AwayTeam, FTHG and FTAG, with any
+// other columns ignored. "Home" and "Away" are accepted for the team columns
+// and "HG" and "AG" for the goals. Blank lines and matches without a score
+// are skipped; the rows are returned ordered by date.
+func ParseResults(r io.Reader) ([]ResultRow, error) {
+	const file = "results"
+	records, header, err := readCSV(r, file, "date")
+	if err != nil {
+		return nil, err
+	}
+	homeColumn, err := pickColumn(header, file, "hometeam", "home")
+	if err != nil {
+		return nil, err
+	}
+	awayColumn, err := pickColumn(header, file, "awayteam", "away")
+	if err != nil {
+		return nil, err
+	}
+	homeGoalsColumn, err := pickColumn(header, file, "fthg", "hg")
+	if err != nil {
+		return nil, err
+	}
+	awayGoalsColumn, err := pickColumn(header, file, "ftag", "ag")
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ResultRow
+	var problems []RowError
+	for i, record := range records {
+		line := i + 2
+		problem := func(field, message string) {
+			problems = append(problems, RowError{File: file, Row: i + 1, Line: line, Field: field, Message: message})
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		row := ResultRow{
+			Row:      i + 1,
+			Line:     line,
+			HomeTeam: field(record, header, homeColumn),
+			AwayTeam: field(record, header, awayColumn),
+		}
+		homeGoals := field(record, header, homeGoalsColumn)
+		awayGoals := field(record, header, awayGoalsColumn)
+		if homeGoals == "" && awayGoals == "" {
+			continue
+		}
+
+		valid := true
+		date, err := parseResultDate(field(record, header, "date"))
+		if err != nil {
+			problem("Date", err.Error())
+			valid = false
+		}
+		row.Date = date
+		if row.HomeTeam == "" || row.AwayTeam == "" {
+			problem("HomeTeam", "both teams are required")
+			valid = false
+		} else if strings.EqualFold(row.HomeTeam, row.AwayTeam) {
+			problem("AwayTeam", "a team cannot play itself")
+			valid = false
+		}
+		for _, goals := range []struct {
+			name   string
+			value  string
+			target *int
+		}{
+			{"FTHG", homeGoals, &row.HomeGoals},
+			{"FTAG", awayGoals, &row.AwayGoals},
+		} {
+			number, err := strconv.Atoi(goals.value)
+			if err != nil || number < 0 {
+				problem(goals.name, fmt.Sprintf("%q is not a number of goals", goals.value))
+				valid = false
+				continue
+			}
+			*goals.target = number
+		}
+		if !valid {
+			continue
+		}
+
+		row.SeasonYear = date.Year()
+		if date.Month() < seasonStartMonth {
+			row.SeasonYear--
+		}
+		results = append(results, row)
+	}
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Rows: problems}
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("%w: %s: the file has no played matches", ErrInvalidImport, file)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Date.Before(results[j].Date) })
+	return results, nil
+}
+
+// pickColumn returns the first of the given header names that the file has
+func pickColumn(header map[string]int, file string, names ...string) (string, error) {
+	for _, name := range names {
+		if _, ok := header[name]; ok {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s: the header has no %q column", ErrInvalidImport, file, names[0])
+}
+
+// parseResultDate accepts the dd/mm/yy and dd/mm/yyyy dates of football-data
+// files as well as ISO dates
+func parseResultDate(value string) (time.Time, error) {
+	for _, layout := range []string{"02/01/2006", "02/01/06", "2/1/2006", "2/1/06", "2006-01-02"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date", value)
+}
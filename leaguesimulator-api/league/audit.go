@@ -0,0 +1,90 @@
+package league
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"leaguesimulator/db"
+	"leaguesimulator/models"
+)
+
+const defaultActor = "anonymous"
+
+// changeResult stores a new score for a played match and records the change
+// in the audit log. Scorers entered for the old score are replaced by the
+// given ones, if any. The caller recalculates the standings.
+func (lm *LeagueManager) changeResult(match *models.Match, homeGoals, awayGoals int, reason, actor string, revertOf int, scorers []models.Goal) error {
+	if match.HomeGoals == homeGoals && match.AwayGoals == awayGoals {
+		return nil
+	}
+	if actor == "" {
+		actor = defaultActor
+	}
+
+	edit := models.MatchEdit{
+		MatchID:      match.ID,
+		OldHomeGoals: match.HomeGoals,
+		OldAwayGoals: match.AwayGoals,
+		NewHomeGoals: homeGoals,
+		NewAwayGoals: awayGoals,
+		Reason:       reason,
+		Actor:        actor,
+		RevertOf:     revertOf,
+	}
+	updated := *match
+	updated.HomeGoals = homeGoals
+	updated.AwayGoals = awayGoals
+	if _, err := db.EditMatchResult(updated, edit, scorers); err != nil {
+		return err
+	}
+	*match = updated
+	return nil
+}
+
+// GetMatchHistory returns a match with every recorded change of its result
+func (lm *LeagueManager) GetMatchHistory(matchID int) (models.Match, []models.MatchEdit, error) {
+	match, err := lm.GetMatchById(matchID)
+	if err != nil {
+		return models.Match{}, nil, err
+	}
+
+	edits, err := db.GetMatchEdits(matchID)
+	if err != nil {
+		return models.Match{}, nil, err
+	}
+	return match, edits, nil
+}
+
+// RevertMatchEdit restores the score a match had before the given edit. The
+// revert is itself recorded as a new edit.
+func (lm *LeagueManager) RevertMatchEdit(matchID, editID int, reason, actor string) (models.Match, error) {
+	lm.GetMatches()
+
+	match := lm.findMatch(matchID)
+	if match == nil {
+		return models.Match{}, fmt.Errorf("%w: match %d", ErrMatchNotFound, matchID)
+	}
+
+	edit, err := db.GetMatchEdit(editID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && edit.MatchID != matchID) {
+		return models.Match{}, fmt.Errorf("%w: edit %d does not belong to match %d", ErrInvalidResult, editID, matchID)
+	}
+	if err != nil {
+		return models.Match{}, err
+	}
+	if match.HomeGoals == edit.OldHomeGoals && match.AwayGoals == edit.OldAwayGoals {
+		return models.Match{}, fmt.Errorf("%w: match %d already has the score %d-%d", ErrInvalidResult, matchID, edit.OldHomeGoals, edit.OldAwayGoals)
+	}
+
+	if reason == "" {
+		reason = fmt.Sprintf("Revert of edit %d", editID)
+	}
+	if err := lm.changeResult(match, edit.OldHomeGoals, edit.OldAwayGoals, reason, actor, editID, nil); err != nil {
+		return models.Match{}, err
+	}
+	reverted := *match
+
+	lm.recalculateTeamStats()
+	return reverted, nil
+}
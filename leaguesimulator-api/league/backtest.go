@@ -0,0 +1,509 @@
+package league
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"leaguesimulator/db"
+	"leaguesimulator/models"
+	"leaguesimulator/prediction"
+	"leaguesimulator/ratings"
+)
+
+const (
+	BacktestModelPoisson   = "poisson"
+	BacktestModelBaseRate  = "base_rate"
+	BacktestModelPredictor = "predictor"
+
+	// defaultMinTrainingMatches is how many earlier matches a week needs before it is predicted
+	defaultMinTrainingMatches = 30
+	calibrationBins           = 10
+	// minProbability keeps the log loss finite when a model rules an outcome out
+	minProbability = 1e-15
+)
+
+var (
+	// ErrInvalidBacktest is returned for backtest options that cannot be run
+	ErrInvalidBacktest = errors.New("invalid backtest")
+	// ErrBacktestNotFound is returned when no backtest report has the requested ID
+	ErrBacktestNotFound = errors.New("backtest report not found")
+)
+
+// BacktestConfig is one model configuration to backtest. Name defaults to a
+// description of the model and its settings.
+type BacktestConfig struct {
+	Name           string  `json:"name"`
+	Model          string  `json:"model"`
+	HalfLifeWeeks  float64 `json:"half_life_weeks,omitempty"`
+	Regularization float64 `json:"regularization,omitempty"`
+}
+
+// BacktestOptions choose the configurations and the seasons to replay.
+// Without seasons every stored season before the running one is replayed.
+type BacktestOptions struct {
+	Configs            []BacktestConfig `json:"configs"`
+	Seasons            []int            `json:"seasons"`
+	MinTrainingMatches int              `json:"min_training_matches"`
+}
+
+// BacktestMetrics score a set of home win, draw and away win forecasts.
+// BrierScore sums the squared errors over the three outcomes.
+type BacktestMetrics struct {
+	Predictions int     `json:"predictions"`
+	Accuracy    float64 `json:"accuracy"`
+	BrierScore  float64 `json:"brier_score"`
+	LogLoss     float64 `json:"log_loss"`
+}
+
+// SeasonBacktest is the score of one replayed season
+type SeasonBacktest struct {
+	Season int `json:"season"`
+	BacktestMetrics
+}
+
+// CalibrationBin compares forecasts within a probability range with how
+// often those outcomes happened. Every outcome of every match is a forecast.
+type CalibrationBin struct {
+	From              float64 `json:"from"`
+	To                float64 `json:"to"`
+	Forecasts         int     `json:"forecasts"`
+	MeanPredicted     float64 `json:"mean_predicted"`
+	ObservedFrequency float64 `json:"observed_frequency"`
+}
+
+// BacktestReport is the result of replaying seasons with one configuration
+type BacktestReport struct {
+	ID                 int              `json:"id,omitempty"`
+	Config             BacktestConfig   `json:"config"`
+	Seasons            []int            `json:"seasons"`
+	MinTrainingMatches int              `json:"min_training_matches"`
+	Skipped            int              `json:"skipped"`
+	Overall            BacktestMetrics  `json:"overall"`
+	BySeason           []SeasonBacktest `json:"by_season"`
+	Calibration        []CalibrationBin `json:"calibration"`
+	CreatedAt          time.Time        `json:"created_at"`
+}
+
+// DefaultBacktestConfigs compares the prediction model with the base rates
+// and the Poisson model with and without time decay
+func DefaultBacktestConfigs() []BacktestConfig {
+	return []BacktestConfig{
+		{Model: BacktestModelPredictor},
+		{Model: BacktestModelBaseRate},
+		{Model: BacktestModelPoisson},
+		{Model: BacktestModelPoisson, HalfLifeWeeks: -1},
+	}
+}
+
+// forecast is a model's home win, draw and away win chances for one match;
+// ok is false when the model cannot predict the match
+type forecast struct {
+	probabilities [3]float64
+	ok            bool
+}
+
+// forecaster predicts the matches of one week from the matches played before it
+type forecaster func(training, week []models.HistoricalMatch) ([]forecast, error)
+
+// RunBacktest replays the chosen seasons week by week. Each week is
+// predicted by every configuration from the matches played before it, in
+// that season and earlier ones, and scored once its results are known. One
+// report per configuration is saved, all of them together.
+func (lm *LeagueManager) RunBacktest(options BacktestOptions) ([]BacktestReport, error) {
+	defaults := len(options.Configs) == 0
+	if defaults {
+		options.Configs = DefaultBacktestConfigs()
+	}
+	if options.MinTrainingMatches == 0 {
+		options.MinTrainingMatches = defaultMinTrainingMatches
+	}
+	if options.MinTrainingMatches < 1 {
+		return nil, fmt.Errorf("%w: min_training_matches must be positive", ErrInvalidBacktest)
+	}
+
+	matches, err := lm.archivedMatches()
+	if err != nil {
+		return nil, err
+	}
+	seasons, err := backtestSeasons(matches, options.Seasons)
+	if err != nil {
+		return nil, err
+	}
+
+	forecasters := make([]forecaster, len(options.Configs))
+	for i := range options.Configs {
+		config := &options.Configs[i]
+		if forecasters[i], err = newForecaster(config); err != nil {
+			return nil, err
+		}
+	}
+
+	replay := make(map[int]bool)
+	for _, season := range seasons {
+		replay[season] = true
+	}
+	reports := make([]BacktestReport, len(options.Configs))
+	scores := make([]map[int]*scorer, len(options.Configs))
+	overall := make([]*scorer, len(options.Configs))
+	for i, config := range options.Configs {
+		reports[i] = BacktestReport{Config: config, Seasons: seasons, MinTrainingMatches: options.MinTrainingMatches}
+		scores[i] = make(map[int]*scorer)
+		overall[i] = &scorer{}
+	}
+
+	// Walk the weeks in order; matches[:start] is everything played before the week
+	for start := 0; start < len(matches); {
+		end := start
+		for end < len(matches) && matches[end].Season == matches[start].Season && matches[end].Week == matches[start].Week {
+			end++
+		}
+		season := matches[start].Season
+		if replay[season] {
+			training, week := matches[:start], matches[start:end]
+			for i, predict := range forecasters {
+				if scores[i][season] == nil {
+					scores[i][season] = &scorer{}
+				}
+				if len(training) < options.MinTrainingMatches {
+					reports[i].Skipped += len(week)
+					continue
+				}
+				forecasts, err := predict(training, week)
+				if err != nil {
+					return nil, err
+				}
+				for j, match := range week {
+					if !forecasts[j].ok {
+						reports[i].Skipped++
+						continue
+					}
+					outcome := matchOutcome(match)
+					overall[i].add(forecasts[j].probabilities, outcome)
+					scores[i][season].add(forecasts[j].probabilities, outcome)
+				}
+			}
+		}
+		start = end
+	}
+
+	// A default configuration that cannot predict this league, such as the
+	// prediction model with teams it does not know, is left out
+	kept := 0
+	for i := range reports {
+		if overall[i].predictions > 0 {
+			reports[kept], scores[kept], overall[kept] = reports[i], scores[i], overall[i]
+			kept++
+			continue
+		}
+		if !defaults {
+			return nil, fmt.Errorf("%w: %s made no predictions; lower min_training_matches or add earlier seasons", ErrInvalidBacktest, reports[i].Config.Name)
+		}
+	}
+	if kept == 0 {
+		return nil, fmt.Errorf("%w: no model made any predictions; lower min_training_matches or add earlier seasons", ErrInvalidBacktest)
+	}
+	reports = reports[:kept]
+
+	now := time.Now().UTC()
+	stored := make([]models.BacktestReport, len(reports))
+	for i := range reports {
+		reports[i].Overall = overall[i].metrics()
+		reports[i].Calibration = overall[i].calibration()
+		reports[i].BySeason = []SeasonBacktest{}
+		for _, season := range seasons {
+			if score := scores[i][season]; score != nil && score.predictions > 0 {
+				reports[i].BySeason = append(reports[i].BySeason, SeasonBacktest{Season: season, BacktestMetrics: score.metrics()})
+			}
+		}
+		reports[i].CreatedAt = now
+
+		document, err := json.Marshal(reports[i])
+		if err != nil {
+			return nil, err
+		}
+		stored[i] = models.BacktestReport{
+			Name:        reports[i].Config.Name,
+			Model:       reports[i].Config.Model,
+			Predictions: reports[i].Overall.Predictions,
+			Accuracy:    reports[i].Overall.Accuracy,
+			BrierScore:  reports[i].Overall.BrierScore,
+			LogLoss:     reports[i].Overall.LogLoss,
+			Document:    document,
+		}
+	}
+
+	ids, err := db.CreateBacktestReports(stored)
+	if err != nil {
+		return nil, err
+	}
+	for i, id := range ids {
+		reports[i].ID = id
+	}
+	return reports, nil
+}
+
+// ListBacktestReports returns the saved reports without their details, newest first
+func ListBacktestReports() ([]models.BacktestReport, error) {
+	return db.GetAllBacktestReports()
+}
+
+// GetBacktestReport loads one saved report in full
+func GetBacktestReport(reportID int) (*BacktestReport, error) {
+	stored, err := db.GetBacktestReport(reportID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: report %d", ErrBacktestNotFound, reportID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var report BacktestReport
+	if err := json.Unmarshal(stored.Document, &report); err != nil {
+		return nil, err
+	}
+	report.ID = stored.ID
+	report.CreatedAt = stored.CreatedAt
+	return &report, nil
+}
+
+// backtestSeasons checks the requested seasons, or picks every stored
+// season before the running one
+func backtestSeasons(matches []models.HistoricalMatch, requested []int) ([]int, error) {
+	stored := make(map[int]bool)
+	for _, match := range matches {
+		stored[match.Season] = true
+	}
+	if len(requested) == 0 {
+		for season := range stored {
+			if season != db.CurrentSeason {
+				requested = append(requested, season)
+			}
+		}
+		if len(requested) == 0 {
+			return nil, fmt.Errorf("%w: there are no finished seasons to replay", ErrInvalidBacktest)
+		}
+	}
+	for _, season := range requested {
+		if !stored[season] {
+			return nil, fmt.Errorf("%w: no matches are stored for season %d", ErrInvalidBacktest, season)
+		}
+	}
+	seasons := append([]int{}, requested...)
+	sort.Ints(seasons)
+	return seasons, nil
+}
+
+// newForecaster checks a configuration, fills in its name and builds its model
+func newForecaster(config *BacktestConfig) (forecaster, error) {
+	switch config.Model {
+	case BacktestModelBaseRate:
+		if config.Name == "" {
+			config.Name = BacktestModelBaseRate
+		}
+		return baseRateForecast, nil
+	case BacktestModelPoisson:
+		if config.Regularization < 0 {
+			return nil, fmt.Errorf("%w: regularization cannot be negative", ErrInvalidBacktest)
+		}
+		if config.HalfLifeWeeks == 0 {
+			config.HalfLifeWeeks = ratings.DefaultHalfLife
+		}
+		if config.Name == "" {
+			config.Name = fmt.Sprintf("%s half-life %g", BacktestModelPoisson, config.HalfLifeWeeks)
+			if config.HalfLifeWeeks < 0 {
+				config.Name = BacktestModelPoisson + " no decay"
+			}
+		}
+		return poissonForecaster(*config), nil
+	case BacktestModelPredictor:
+		if config.Name == "" {
+			config.Name = BacktestModelPredictor
+		}
+		return predictorForecaster(prediction.NewAdvancedPredictionService()), nil
+	default:
+		return nil, fmt.Errorf("%w: model must be %q, %q or %q", ErrInvalidBacktest, BacktestModelPredictor, BacktestModelPoisson, BacktestModelBaseRate)
+	}
+}
+
+// baseRateForecast predicts every match with the share of home wins, draws
+// and away wins so far, each counted from one to avoid certainties
+func baseRateForecast(training, week []models.HistoricalMatch) ([]forecast, error) {
+	counts := [3]float64{1, 1, 1}
+	for _, match := range training {
+		counts[matchOutcome(match)]++
+	}
+	total := counts[0] + counts[1] + counts[2]
+	forecasts := make([]forecast, len(week))
+	for i := range week {
+		forecasts[i] = forecast{[3]float64{counts[0] / total, counts[1] / total, counts[2] / total}, true}
+	}
+	return forecasts, nil
+}
+
+// poissonForecaster fits the Poisson ratings once per week and prices every
+// match of the week with them. Weeks with too few matches to fit and matches
+// with a team that has not played yet are skipped.
+func poissonForecaster(config BacktestConfig) forecaster {
+	return func(training, week []models.HistoricalMatch) ([]forecast, error) {
+		forecasts := make([]forecast, len(week))
+		fit, err := ratings.FitPoisson(timeline(training), ratings.Options{
+			HalfLife:       config.HalfLifeWeeks,
+			Regularization: config.Regularization,
+		})
+		if errors.Is(err, ratings.ErrNoData) {
+			return forecasts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i, match := range week {
+			homeRate, awayRate, ok := fit.ExpectedGoals(match.HomeTeam, match.AwayTeam)
+			if !ok {
+				continue
+			}
+			homeWin, draw, awayWin := ratings.ResultProbabilities(homeRate, awayRate)
+			forecasts[i] = normalizedForecast(homeWin, draw, awayWin)
+		}
+		return forecasts, nil
+	}
+}
+
+// predictorForecaster runs the prediction model once per week with only the
+// earlier matches as its history, as /predict would have seen them. Matches
+// with a team the model does not know are skipped.
+func predictorForecaster(service *prediction.AdvancedPredictionService) forecaster {
+	return func(training, week []models.HistoricalMatch) ([]forecast, error) {
+		fixtures := make([]prediction.Fixture, len(week))
+		for i, match := range week {
+			fixtures[i] = prediction.Fixture{HomeTeam: match.HomeTeam, AwayTeam: match.AwayTeam, Week: match.Week}
+		}
+		predicted, err := service.PredictFixtures(training, fixtures)
+		if err != nil {
+			return nil, err
+		}
+
+		forecasts := make([]forecast, len(week))
+		for i, chances := range predicted {
+			if chances != nil {
+				forecasts[i] = normalizedForecast(chances.HomeWin, chances.Draw, chances.AwayWin)
+			}
+		}
+		return forecasts, nil
+	}
+}
+
+// normalizedForecast scales three outcome chances to sum to one
+func normalizedForecast(homeWin, draw, awayWin float64) forecast {
+	total := homeWin + draw + awayWin
+	if total <= 0 {
+		return forecast{}
+	}
+	return forecast{[3]float64{homeWin / total, draw / total, awayWin / total}, true}
+}
+
+// matchOutcome is 0 for a home win, 1 for a draw and 2 for an away win
+func matchOutcome(match models.HistoricalMatch) int {
+	switch {
+	case match.HomeGoals > match.AwayGoals:
+		return 0
+	case match.HomeGoals == match.AwayGoals:
+		return 1
+	}
+	return 2
+}
+
+// scorer adds up the scores of forecasts
+type scorer struct {
+	predictions int
+	correct     int
+	brier       float64
+	logLoss     float64
+	bins        [calibrationBins]struct {
+		forecasts int
+		predicted float64
+		happened  int
+	}
+}
+
+func (s *scorer) add(probabilities [3]float64, outcome int) {
+	s.predictions++
+	best := 0
+	for i, p := range probabilities {
+		if p > probabilities[best] {
+			best = i
+		}
+		observed := 0.0
+		if i == outcome {
+			observed = 1
+		}
+		s.brier += (p - observed) * (p - observed)
+
+		bin := int(p * calibrationBins)
+		if bin == calibrationBins {
+			bin--
+		}
+		s.bins[bin].forecasts++
+		s.bins[bin].predicted += p
+		if i == outcome {
+			s.bins[bin].happened++
+		}
+	}
+	if best == outcome {
+		s.correct++
+	}
+	s.logLoss -= math.Log(math.Max(probabilities[outcome], minProbability))
+}
+
+func (s *scorer) metrics() BacktestMetrics {
+	n := float64(s.predictions)
+	return BacktestMetrics{
+		Predictions: s.predictions,
+		Accuracy:    prediction.Round(float64(s.correct)/n, 4),
+		BrierScore:  prediction.Round(s.brier/n, 4),
+		LogLoss:     prediction.Round(s.logLoss/n, 4),
+	}
+}
+
+func (s *scorer) calibration() []CalibrationBin {
+	bins := []CalibrationBin{}
+	for i, bin := range s.bins {
+		if bin.forecasts == 0 {
+			continue
+		}
+		bins = append(bins, CalibrationBin{
+			From:              float64(i) / calibrationBins,
+			To:                float64(i+1) / calibrationBins,
+			Forecasts:         bin.forecasts,
+			MeanPredicted:     prediction.Round(bin.predicted/float64(bin.forecasts), 4),
+			ObservedFrequency: prediction.Round(float64(bin.happened)/float64(bin.forecasts), 4),
+		})
+	}
+	return bins
+}
+
+// ParseBacktestConfig reads a configuration written as
+// model[:half_life_weeks[:regularization]], such as "poisson:19"
+func ParseBacktestConfig(value string) (BacktestConfig, error) {
+	parts := strings.Split(value, ":")
+	config := BacktestConfig{Model: strings.TrimSpace(parts[0])}
+	for i, target := range []*float64{&config.HalfLifeWeeks, &config.Regularization} {
+		if len(parts) <= i+1 {
+			break
+		}
+		number, err := strconv.ParseFloat(strings.TrimSpace(parts[i+1]), 64)
+		if err != nil {
+			return config, fmt.Errorf("%w: %q is not a number in %q", ErrInvalidBacktest, parts[i+1], value)
+		}
+		*target = number
+	}
+	if len(parts) > 3 {
+		return config, fmt.Errorf("%w: %q has too many parts", ErrInvalidBacktest, value)
+	}
+	return config, nil
+}
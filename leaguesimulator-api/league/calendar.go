@@ -0,0 +1,302 @@
+package league
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"leaguesimulator/db"
+	"leaguesimulator/models"
+)
+
+const settingCalendarConfig = "calendar_config"
+
+// ErrInvalidCalendar is returned when a calendar configuration cannot be used
+var ErrInvalidCalendar = errors.New("invalid calendar configuration")
+
+// MatchdaySlot is a day of the week on which a round is played. The
+// matches of a round cycle through the kick-off times in order.
+type MatchdaySlot struct {
+	Weekday  string   `json:"weekday"`
+	KickOffs []string `json:"kick_offs"`
+}
+
+// CalendarConfig turns week numbers into real dates: week 1 is played on the
+// first matchday slot on or after the season start, and every following week
+// on the next slot in date order (e.g. Saturdays plus mid-week rounds).
+type CalendarConfig struct {
+	SeasonStart string         `json:"season_start"`
+	Timezone    string         `json:"timezone"`
+	Matchdays   []MatchdaySlot `json:"matchdays"`
+}
+
+// defaultCalendar plays every round on Saturday afternoons starting next Saturday
+func defaultCalendar() CalendarConfig {
+	start := time.Now().UTC()
+	for start.Weekday() != time.Saturday {
+		start = start.AddDate(0, 0, 1)
+	}
+	return CalendarConfig{
+		SeasonStart: start.Format("2006-01-02"),
+		Timezone:    "UTC",
+		Matchdays: []MatchdaySlot{
+			{Weekday: "Saturday", KickOffs: []string{"15:00", "17:30"}},
+		},
+	}
+}
+
+// loadCalendarSettings restores the stored calendar, falling back to the default one
+func (lm *LeagueManager) loadCalendarSettings() {
+	lm.Calendar = defaultCalendar()
+
+	value, ok, err := db.GetLeagueSetting(settingCalendarConfig)
+	if err != nil || !ok {
+		return
+	}
+
+	var config CalendarConfig
+	if err := json.Unmarshal([]byte(value), &config); err != nil {
+		log.Printf("Ignoring invalid calendar configuration: %v", err)
+		return
+	}
+	if err := config.validate(); err != nil {
+		log.Printf("Ignoring invalid calendar configuration: %v", err)
+		return
+	}
+	lm.Calendar = config
+}
+
+func (config CalendarConfig) validate() error {
+	if _, err := time.Parse("2006-01-02", config.SeasonStart); err != nil {
+		return fmt.Errorf("%w: season_start must be a YYYY-MM-DD date", ErrInvalidCalendar)
+	}
+	if _, err := time.LoadLocation(config.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidCalendar, config.Timezone)
+	}
+	if len(config.Matchdays) == 0 {
+		return fmt.Errorf("%w: at least one matchday is required", ErrInvalidCalendar)
+	}
+
+	seen := make(map[time.Weekday]bool)
+	for _, slot := range config.Matchdays {
+		weekday, ok := parseWeekday(slot.Weekday)
+		if !ok {
+			return fmt.Errorf("%w: unknown weekday %q", ErrInvalidCalendar, slot.Weekday)
+		}
+		if seen[weekday] {
+			return fmt.Errorf("%w: %s is listed twice", ErrInvalidCalendar, weekday)
+		}
+		seen[weekday] = true
+
+		if len(slot.KickOffs) == 0 {
+			return fmt.Errorf("%w: %s needs at least one kick-off time", ErrInvalidCalendar, weekday)
+		}
+		for _, kickOff := range slot.KickOffs {
+			if _, err := time.Parse("15:04", kickOff); err != nil {
+				return fmt.Errorf("%w: kick-off %q must be HH:MM", ErrInvalidCalendar, kickOff)
+			}
+		}
+	}
+	return nil
+}
+
+// matchdays returns the date and slot of every week from 1 to weeks
+func (config CalendarConfig) matchdays(weeks int) ([]time.Time, []MatchdaySlot) {
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	day, err := time.ParseInLocation("2006-01-02", config.SeasonStart, location)
+	if err != nil {
+		return nil, nil
+	}
+
+	slots := make(map[time.Weekday]MatchdaySlot)
+	for _, slot := range config.Matchdays {
+		if weekday, ok := parseWeekday(slot.Weekday); ok {
+			slots[weekday] = slot
+		}
+	}
+	if len(slots) == 0 {
+		return nil, nil
+	}
+
+	var dates []time.Time
+	var weekSlots []MatchdaySlot
+	for len(dates) < weeks {
+		if slot, ok := slots[day.Weekday()]; ok {
+			dates = append(dates, day)
+			weekSlots = append(weekSlots, slot)
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return dates, weekSlots
+}
+
+// scheduleFixtures gives every unplayed fixture a kick-off time from the
+// calendar and the home team's venue
+func (lm *LeagueManager) scheduleFixtures(fixtures []models.Match) {
+	maxWeek := 0
+	for _, fixture := range fixtures {
+		if fixture.Week > maxWeek {
+			maxWeek = fixture.Week
+		}
+	}
+
+	dates, slots := lm.Calendar.matchdays(maxWeek)
+	if len(dates) < maxWeek {
+		return
+	}
+
+	venues := make(map[string]int)
+	for _, team := range lm.Teams {
+		venues[team.Name] = team.VenueID
+	}
+
+	matchIndex := make(map[int]int)
+	for i := range fixtures {
+		fixture := &fixtures[i]
+		index := matchIndex[fixture.Week]
+		matchIndex[fixture.Week]++
+		if fixture.Played || fixture.Postponed || fixture.Week < 1 {
+			continue
+		}
+
+		slot := slots[fixture.Week-1]
+		kickOff, _ := time.Parse("15:04", slot.KickOffs[index%len(slot.KickOffs)])
+		date := dates[fixture.Week-1]
+		start := time.Date(date.Year(), date.Month(), date.Day(), kickOff.Hour(), kickOff.Minute(), 0, 0, date.Location())
+
+		fixture.KickOff = &start
+		fixture.VenueID = venues[fixture.HomeTeam]
+	}
+}
+
+// kickOff returns the calendar's kick-off time of the given week, taking the
+// slot's first kick-off. It returns nil when the calendar cannot reach that week.
+func (config CalendarConfig) kickOff(week int) *time.Time {
+	if week < 1 {
+		return nil
+	}
+	dates, slots := config.matchdays(week)
+	if len(dates) < week {
+		return nil
+	}
+
+	date, slot := dates[week-1], slots[week-1]
+	kickOff, _ := time.Parse("15:04", slot.KickOffs[0])
+	start := time.Date(date.Year(), date.Month(), date.Day(), kickOff.Hour(), kickOff.Minute(), 0, 0, date.Location())
+	return &start
+}
+
+// ConfigureCalendar stores a new calendar and reschedules every unplayed fixture with it
+func (lm *LeagueManager) ConfigureCalendar(config CalendarConfig) error {
+	if config.Timezone == "" {
+		config.Timezone = "UTC"
+	}
+	if err := config.validate(); err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err := db.SaveLeagueSetting(settingCalendarConfig, string(value)); err != nil {
+		return err
+	}
+	lm.Calendar = config
+
+	return lm.RescheduleFixtures()
+}
+
+// RescheduleFixtures recomputes kick-off times and venues of unplayed fixtures
+func (lm *LeagueManager) RescheduleFixtures() error {
+	teams, err := db.GetAllTeams()
+	if err == nil && len(teams) > 0 {
+		for i := range lm.Teams {
+			for _, team := range teams {
+				if team.Name == lm.Teams[i].Name {
+					lm.Teams[i].VenueID = team.VenueID
+				}
+			}
+		}
+	}
+
+	fixtures := lm.GetMatches()
+	lm.scheduleFixtures(fixtures)
+	for _, fixture := range fixtures {
+		if fixture.Played {
+			continue
+		}
+		if err := db.UpdateMatchSchedule(fixture); err != nil {
+			return err
+		}
+	}
+
+	lm.GetMatches()
+	return nil
+}
+
+// CreateVenue registers a new stadium
+func CreateVenue(name, city string, capacity int) (*models.Venue, error) {
+	if capacity < 0 {
+		return nil, fmt.Errorf("%w: capacity cannot be negative", ErrInvalidCalendar)
+	}
+
+	venue := models.Venue{Name: name, City: city, Capacity: capacity, Teams: []string{}}
+	id, err := db.CreateVenue(venue)
+	if err != nil {
+		return nil, err
+	}
+	venue.ID = id
+	return &venue, nil
+}
+
+// ListVenues returns every venue with its home teams
+func ListVenues() ([]models.Venue, error) {
+	return db.GetAllVenues()
+}
+
+// AssignVenue makes a venue the home ground of a team and moves its unplayed home fixtures there
+func (lm *LeagueManager) AssignVenue(teamName string, venueID int) error {
+	team := lm.findTeam(teamName)
+	if team == nil {
+		return fmt.Errorf("%w: unknown team %q", ErrInvalidCalendar, teamName)
+	}
+
+	if venueID != 0 {
+		venues, err := db.GetAllVenues()
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, venue := range venues {
+			if venue.ID == venueID {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: venue %d does not exist", ErrInvalidCalendar, venueID)
+		}
+	}
+
+	if err := db.AssignTeamVenue(teamName, venueID); err != nil {
+		return err
+	}
+	team.VenueID = venueID
+
+	return lm.RescheduleFixtures()
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), name) || strings.EqualFold(day.String()[:3], name) {
+			return day, true
+		}
+	}
+	return time.Sunday, false
+}
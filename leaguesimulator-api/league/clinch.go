@@ -0,0 +1,439 @@
+package league
+
+import (
+	"sort"
+)
+
+const (
+	StatusClinchedTitle       = "clinched_title"
+	StatusClinchedTopN        = "clinched_top_n"
+	StatusInContention        = "in_contention"
+	StatusEliminatedFromTitle = "eliminated_from_title"
+	StatusEliminated          = "eliminated"
+
+	// clinchSearchLimit bounds the number of partial results tried by a single search
+	clinchSearchLimit = 200000
+)
+
+// TeamStatus is the mathematical position of one team given every way the
+// remaining fixtures can end. While fixtures remain, ties on points count
+// against the team, since goal difference cannot be known in advance.
+type TeamStatus struct {
+	Team               string `json:"team"`
+	Position           int    `json:"position"`
+	Points             int    `json:"points"`
+	MaxPoints          int    `json:"max_points"`
+	RemainingMatches   int    `json:"remaining_matches"`
+	ClinchedTitle      bool   `json:"clinched_title"`
+	ClinchedTopN       bool   `json:"clinched_top_n"`
+	Eliminated         bool   `json:"eliminated"`
+	EliminatedFromTopN bool   `json:"eliminated_from_top_n"`
+	MagicNumber        *int   `json:"magic_number"`
+	Status             string `json:"status"`
+}
+
+// SeasonStatus holds the clinch and elimination status of every team. Exact
+// is false when a search was cut short; the teams involved are then reported
+// as still in contention.
+type SeasonStatus struct {
+	TopN  int          `json:"top_n"`
+	Exact bool         `json:"exact"`
+	Teams []TeamStatus `json:"teams"`
+}
+
+type remainingGame struct {
+	home, away int
+}
+
+// DefaultTopN is the number of places the top-N status refers to: the
+// playoff places when playoffs are enabled, otherwise the top four
+func (lm *LeagueManager) DefaultTopN() int {
+	topN := 4
+	if lm.Playoffs.Enabled {
+		topN = lm.Playoffs.Teams
+	}
+	if topN > len(lm.Teams)-1 {
+		topN = len(lm.Teams) - 1
+	}
+	if topN < 1 {
+		topN = 1
+	}
+	return topN
+}
+
+// GetSeasonStatus works out who has clinched the title or a top-N place, who
+// is mathematically eliminated and every team's magic number
+func (lm *LeagueManager) GetSeasonStatus(topN int) SeasonStatus {
+	standings := lm.GetStandings()
+	if topN < 1 || topN >= len(standings) {
+		topN = lm.DefaultTopN()
+	}
+
+	index := make(map[string]int)
+	names := make([]string, len(standings))
+	points := make([]int, len(standings))
+	for i, standing := range standings {
+		index[standing.Name] = i
+		names[i] = standing.Name
+		points[i] = standing.Points
+	}
+
+	var games []remainingGame
+	for _, match := range lm.Matches {
+		home, okHome := index[match.HomeTeam]
+		away, okAway := index[match.AwayTeam]
+		if match.Played || !okHome || !okAway {
+			continue
+		}
+		games = append(games, remainingGame{home, away})
+	}
+	return seasonStatus(names, points, games, topN)
+}
+
+// seasonStatus works out the status of teams given in table order. Once no
+// fixtures remain the table order, with its tiebreakers, is final: the
+// leader has clinched and every other team is eliminated.
+func seasonStatus(names []string, points []int, games []remainingGame, topN int) SeasonStatus {
+	remaining := make([]int, len(points))
+	for _, game := range games {
+		remaining[game.home]++
+		remaining[game.away]++
+	}
+
+	status := SeasonStatus{TopN: topN, Exact: true, Teams: []TeamStatus{}}
+	for team, name := range names {
+		teamStatus := TeamStatus{
+			Team:             name,
+			Position:         team + 1,
+			Points:           points[team],
+			MaxPoints:        points[team] + 3*remaining[team],
+			RemainingMatches: remaining[team],
+		}
+
+		if len(games) == 0 {
+			teamStatus.ClinchedTitle = team == 0
+			teamStatus.ClinchedTopN = team < topN
+			teamStatus.Eliminated = team != 0
+			teamStatus.EliminatedFromTopN = team >= topN
+		} else {
+			clinchedTitle, exact := canClinch(team, 1, points, games)
+			status.Exact = status.Exact && exact
+			clinchedTop, exact := canClinch(team, topN, points, games)
+			status.Exact = status.Exact && exact
+			aliveTitle, exact := canStayAlive(team, 1, points, games)
+			status.Exact = status.Exact && exact
+			aliveTop, exact := canStayAlive(team, topN, points, games)
+			status.Exact = status.Exact && exact
+
+			teamStatus.ClinchedTitle = clinchedTitle
+			teamStatus.ClinchedTopN = clinchedTop
+			teamStatus.Eliminated = !aliveTitle
+			teamStatus.EliminatedFromTopN = !aliveTop
+		}
+
+		if teamStatus.ClinchedTitle {
+			magic := 0
+			teamStatus.MagicNumber = &magic
+		} else if !teamStatus.Eliminated {
+			magic := magicNumber(team, points, remaining)
+			teamStatus.MagicNumber = &magic
+		}
+
+		switch {
+		case teamStatus.ClinchedTitle:
+			teamStatus.Status = StatusClinchedTitle
+		case teamStatus.Eliminated && teamStatus.EliminatedFromTopN:
+			teamStatus.Status = StatusEliminated
+		case teamStatus.ClinchedTopN:
+			teamStatus.Status = StatusClinchedTopN
+		case teamStatus.Eliminated:
+			teamStatus.Status = StatusEliminatedFromTitle
+		default:
+			teamStatus.Status = StatusInContention
+		}
+
+		status.Teams = append(status.Teams, teamStatus)
+	}
+	return status
+}
+
+// magicNumber is the number of points a team still needs to be sure of the
+// title whatever else happens: one more than the best total any rival can
+// reach, minus the team's current points
+func magicNumber(team int, points []int, remaining []int) int {
+	best := 0
+	for other := range points {
+		if other == team {
+			continue
+		}
+		if max := points[other] + 3*remaining[other]; max > best {
+			best = max
+		}
+	}
+	magic := best - points[team] + 1
+	if magic < 0 {
+		magic = 0
+	}
+	return magic
+}
+
+// canClinch reports whether a team is sure to finish strictly inside the top
+// n on points. It assumes the team loses every remaining match and looks for
+// n rivals that could all reach its points together. The second result is
+// false when the search was cut short.
+func canClinch(team, n int, points []int, games []remainingGame) (bool, bool) {
+	final := append([]int{}, points...)
+	var others []remainingGame
+	for _, game := range games {
+		switch team {
+		case game.home:
+			final[game.away] += 3
+		case game.away:
+			final[game.home] += 3
+		default:
+			others = append(others, game)
+		}
+	}
+	threshold := final[team]
+
+	potential := make([]int, len(points))
+	for _, game := range others {
+		potential[game.home] += 3
+		potential[game.away] += 3
+	}
+
+	var candidates []int
+	for other := range points {
+		if other != team && final[other]+potential[other] >= threshold {
+			candidates = append(candidates, other)
+		}
+	}
+	if len(candidates) < n {
+		return true, true
+	}
+
+	search := &completionSearch{limit: clinchSearchLimit}
+	caught := false
+	forEachSubset(candidates, n, func(members []int) bool {
+		caught = search.reachAll(members, threshold, final, others)
+		return caught || search.exhausted
+	})
+	if search.exhausted {
+		return false, false
+	}
+	return !caught, true
+}
+
+// canStayAlive reports whether some way of finishing the season leaves a team
+// inside the top n on points, ties counting in its favour. It assumes the team
+// wins every remaining match. The second result is false when the search was
+// cut short.
+func canStayAlive(team, n int, points []int, games []remainingGame) (bool, bool) {
+	final := append([]int{}, points...)
+	var others []remainingGame
+	for _, game := range games {
+		switch team {
+		case game.home, game.away:
+			final[team] += 3
+		default:
+			others = append(others, game)
+		}
+	}
+	threshold := final[team]
+
+	// Teams already out of reach take places above the team in any case
+	var forced, free []int
+	for other := range points {
+		if other == team {
+			continue
+		}
+		if final[other] > threshold {
+			forced = append(forced, other)
+		} else {
+			free = append(free, other)
+		}
+	}
+	allowed := n - 1 - len(forced)
+	if allowed < 0 {
+		return false, true
+	}
+	if allowed > len(free) {
+		allowed = len(free)
+	}
+
+	// Try the rivals with the most to gain as the ones allowed to pass the team
+	sort.SliceStable(free, func(i, j int) bool { return final[free[i]] > final[free[j]] })
+
+	search := &completionSearch{limit: clinchSearchLimit}
+	alive := false
+	forEachSubset(free, allowed, func(passing []int) bool {
+		uncapped := make([]bool, len(points))
+		uncapped[team] = true
+		for _, other := range forced {
+			uncapped[other] = true
+		}
+		for _, other := range passing {
+			uncapped[other] = true
+		}
+		alive = search.keepBelow(threshold, uncapped, final, others)
+		return alive || search.exhausted
+	})
+	if search.exhausted {
+		return true, false
+	}
+	return alive, true
+}
+
+// completionSearch tries results for the remaining fixtures, stopping after limit steps
+type completionSearch struct {
+	nodes     int
+	limit     int
+	exhausted bool
+}
+
+func (s *completionSearch) step() bool {
+	s.nodes++
+	if s.nodes > s.limit {
+		s.exhausted = true
+		return false
+	}
+	return true
+}
+
+// keepBelow looks for results that leave every capped team on at most
+// threshold points
+func (s *completionSearch) keepBelow(threshold int, uncapped []bool, points []int, games []remainingGame) bool {
+	current := append([]int{}, points...)
+
+	var dfs func(i int) bool
+	dfs = func(i int) bool {
+		if !s.step() {
+			return false
+		}
+		if i == len(games) {
+			return true
+		}
+
+		game := games[i]
+		slackHome := threshold - current[game.home]
+		slackAway := threshold - current[game.away]
+		if uncapped[game.home] {
+			slackHome = 1 << 30
+		}
+		if uncapped[game.away] {
+			slackAway = 1 << 30
+		}
+
+		// Give the win to the side with more room first
+		outcomes := [][2]int{{3, 0}, {1, 1}, {0, 3}}
+		if slackAway > slackHome {
+			outcomes = [][2]int{{0, 3}, {1, 1}, {3, 0}}
+		}
+		for _, outcome := range outcomes {
+			if outcome[0] > slackHome || outcome[1] > slackAway {
+				continue
+			}
+			current[game.home] += outcome[0]
+			current[game.away] += outcome[1]
+			found := dfs(i + 1)
+			current[game.home] -= outcome[0]
+			current[game.away] -= outcome[1]
+			if found || s.exhausted {
+				return found
+			}
+		}
+		return false
+	}
+	return dfs(0)
+}
+
+// reachAll looks for results that take every member to at least threshold
+// points. Members win all their matches against other teams; only matches
+// between members need to be searched.
+func (s *completionSearch) reachAll(members []int, threshold int, points []int, games []remainingGame) bool {
+	current := append([]int{}, points...)
+	isMember := make([]bool, len(points))
+	for _, member := range members {
+		isMember[member] = true
+	}
+
+	var internal []remainingGame
+	potential := make([]int, len(points))
+	for _, game := range games {
+		switch {
+		case isMember[game.home] && isMember[game.away]:
+			internal = append(internal, game)
+			potential[game.home] += 3
+			potential[game.away] += 3
+		case isMember[game.home]:
+			current[game.home] += 3
+		case isMember[game.away]:
+			current[game.away] += 3
+		}
+	}
+
+	var dfs func(i int) bool
+	dfs = func(i int) bool {
+		if !s.step() {
+			return false
+		}
+		for _, member := range members {
+			if current[member]+potential[member] < threshold {
+				return false
+			}
+		}
+		if i == len(internal) {
+			return true
+		}
+
+		game := internal[i]
+		potential[game.home] -= 3
+		potential[game.away] -= 3
+		defer func() {
+			potential[game.home] += 3
+			potential[game.away] += 3
+		}()
+
+		// Give the win to the side that is further from the threshold first
+		outcomes := [][2]int{{3, 0}, {1, 1}, {0, 3}}
+		if current[game.away] < current[game.home] {
+			outcomes = [][2]int{{0, 3}, {1, 1}, {3, 0}}
+		}
+		for _, outcome := range outcomes {
+			current[game.home] += outcome[0]
+			current[game.away] += outcome[1]
+			found := dfs(i + 1)
+			current[game.home] -= outcome[0]
+			current[game.away] -= outcome[1]
+			if found || s.exhausted {
+				return found
+			}
+		}
+		return false
+	}
+	return dfs(0)
+}
+
+// forEachSubset calls visit with every k-element subset of items in order
+// until visit returns true
+func forEachSubset(items []int, k int, visit func([]int) bool) {
+	if k <= 0 {
+		visit(nil)
+		return
+	}
+	subset := make([]int, k)
+	var walk func(start, depth int) bool
+	walk = func(start, depth int) bool {
+		if depth == k {
+			return visit(subset)
+		}
+		for i := start; i <= len(items)-(k-depth); i++ {
+			subset[depth] = items[i]
+			if walk(i+1, depth+1) {
+				return true
+			}
+		}
+		return false
+	}
+	walk(0, 0)
+}
@@ -0,0 +1,106 @@
+package league
+
+import "testing"
+
+func TestSeasonStatus(t *testing.T) {
+	// noMagic marks a team without a magic number, since it is out of the title race
+	const noMagic = -1
+
+	tests := []struct {
+		name   string
+		points []int
+		games  []remainingGame
+		topN   int
+		status []string
+		magic  []int
+	}{
+		{
+			name:   "tied final table",
+			points: []int{10, 10, 4},
+			topN:   2,
+			status: []string{StatusClinchedTitle, StatusClinchedTopN, StatusEliminated},
+			magic:  []int{0, noMagic, noMagic},
+		},
+		{
+			name:   "leader out of reach",
+			points: []int{11, 4, 3},
+			games:  []remainingGame{{1, 2}, {2, 1}},
+			topN:   2,
+			status: []string{StatusClinchedTitle, StatusEliminatedFromTitle, StatusEliminatedFromTitle},
+			magic:  []int{0, noMagic, noMagic},
+		},
+		{
+			name:   "rival can still draw level on points",
+			points: []int{10, 4, 3},
+			games:  []remainingGame{{1, 2}, {2, 1}},
+			topN:   2,
+			status: []string{StatusClinchedTopN, StatusInContention, StatusEliminatedFromTitle},
+			magic:  []int{1, 7, noMagic},
+		},
+		{
+			name:   "rivals cannot both pass the leader",
+			points: []int{6, 4, 4},
+			games:  []remainingGame{{1, 2}},
+			topN:   2,
+			status: []string{StatusClinchedTopN, StatusInContention, StatusInContention},
+			magic:  []int{2, 4, 4},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			names := []string{"Lions", "Tigers", "Bears"}
+			status := seasonStatus(names, test.points, test.games, test.topN)
+			if !status.Exact {
+				t.Errorf("status is not exact")
+			}
+			if len(status.Teams) != len(names) {
+				t.Fatalf("got %d teams, want %d", len(status.Teams), len(names))
+			}
+			for i, team := range status.Teams {
+				if team.Team != names[i] || team.Position != i+1 {
+					t.Errorf("team %d = %s in position %d", i, team.Team, team.Position)
+				}
+				if team.Status != test.status[i] {
+					t.Errorf("%s status = %s, want %s", team.Team, team.Status, test.status[i])
+				}
+				magic := noMagic
+				if team.MagicNumber != nil {
+					magic = *team.MagicNumber
+				}
+				if magic != test.magic[i] {
+					t.Errorf("%s magic number = %d, want %d", team.Team, magic, test.magic[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCanClinch(t *testing.T) {
+	tests := []struct {
+		name   string
+		team   int
+		n      int
+		points []int
+		games  []remainingGame
+		want   bool
+	}{
+		{name: "no rival can reach the team", team: 0, n: 1, points: []int{11, 4, 3}, games: []remainingGame{{1, 2}, {2, 1}}, want: true},
+		{name: "a tie on points counts against the team", team: 0, n: 1, points: []int{10, 4, 3}, games: []remainingGame{{1, 2}, {2, 1}}, want: false},
+		{name: "either rival can pass alone", team: 0, n: 1, points: []int{6, 4, 4}, games: []remainingGame{{1, 2}}, want: false},
+		{name: "rivals cannot both pass", team: 0, n: 2, points: []int{6, 4, 4}, games: []remainingGame{{1, 2}}, want: true},
+		{name: "team losing its own match", team: 0, n: 1, points: []int{7, 4, 0}, games: []remainingGame{{0, 1}}, want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, exact := canClinch(test.team, test.n, test.points, test.games)
+			if !exact {
+				t.Fatalf("search was cut short")
+			}
+			if got != test.want {
+				t.Errorf("canClinch = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,479 @@
+package league
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"leaguesimulator/db"
+	"leaguesimulator/models"
+)
+
+const (
+	CupStatusAwaitingDraw = "awaiting_draw"
+	CupStatusDrawn        = "drawn"
+	CupStatusCompleted    = "completed"
+
+	DrawSeeded = "seeded"
+	DrawRandom = "random"
+)
+
+// ErrInvalidCup is returned when a cup request breaks the competition rules
+// (bad configuration, drawing before a round is played, ...)
+var ErrInvalidCup = errors.New("invalid cup operation")
+
+type CupRound struct {
+	Round     int             `json:"round"`
+	Name      string          `json:"name"`
+	Ties      []models.CupTie `json:"ties"`
+	Completed bool            `json:"completed"`
+}
+
+// CreateCup registers a knockout cup for the given teams (all teams when empty).
+// Entrants are seeded by strength; the draw method decides whether that seeding is used.
+func CreateCup(name string, teamNames []string, legs int, drawMethod string) (*models.Cup, error) {
+	teams, err := db.GetAllTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	if legs == 0 {
+		legs = 1
+	}
+	if legs != 1 && legs != 2 {
+		return nil, fmt.Errorf("%w: legs must be 1 or 2", ErrInvalidCup)
+	}
+	if drawMethod == "" {
+		drawMethod = DrawSeeded
+	}
+	if drawMethod != DrawSeeded && drawMethod != DrawRandom {
+		return nil, fmt.Errorf("%w: draw method must be %q or %q", ErrInvalidCup, DrawSeeded, DrawRandom)
+	}
+
+	entrants, err := selectTeams(teams, teamNames)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCup, err)
+	}
+	if len(entrants) < 2 {
+		return nil, fmt.Errorf("%w: a cup needs at least 2 teams", ErrInvalidCup)
+	}
+
+	sort.SliceStable(entrants, func(i, j int) bool {
+		return entrants[i].Strength > entrants[j].Strength
+	})
+
+	var seeds []string
+	for _, team := range entrants {
+		seeds = append(seeds, team.Name)
+	}
+
+	return saveNewCup(name, seeds, legs, drawMethod)
+}
+
+// saveNewCup stores a cup whose entrants are already listed in seed order
+func saveNewCup(name string, seeds []string, legs int, drawMethod string) (*models.Cup, error) {
+	cup := newCup(name, seeds, legs, drawMethod)
+	id, err := db.CreateCup(cup)
+	if err != nil {
+		return nil, err
+	}
+	cup.ID = id
+
+	return &cup, nil
+}
+
+// newCup sets up a cup awaiting its first draw without storing it
+func newCup(name string, seeds []string, legs int, drawMethod string) models.Cup {
+	return models.Cup{
+		Name:       name,
+		Legs:       legs,
+		DrawMethod: drawMethod,
+		Entrants:   seeds,
+		Status:     CupStatusAwaitingDraw,
+		Ties:       []models.CupTie{},
+	}
+}
+
+// selectTeams picks the named teams out of all teams, rejecting unknown names and duplicates
+func selectTeams(teams []models.Team, teamNames []string) ([]models.Team, error) {
+	if len(teamNames) == 0 {
+		return append([]models.Team{}, teams...), nil
+	}
+
+	byName := make(map[string]models.Team)
+	for _, team := range teams {
+		byName[team.Name] = team
+	}
+
+	var selected []models.Team
+	seen := make(map[string]bool)
+	for _, name := range teamNames {
+		team, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown team %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("team %q entered twice", name)
+		}
+		seen[name] = true
+		selected = append(selected, team)
+	}
+	return selected, nil
+}
+
+// ListCups returns every cup without its ties
+func ListCups() ([]models.Cup, error) {
+	return db.GetAllCups()
+}
+
+// GetCup loads a cup with all of its drawn ties
+func GetCup(cupID int) (*models.Cup, error) {
+	return db.GetCup(cupID)
+}
+
+// DrawCupRound draws the next round of the cup. The first round places the
+// entrants into a bracket padded to a power of two, giving byes to the top seeds.
+func DrawCupRound(cup *models.Cup) ([]models.CupTie, error) {
+	drawn := copyCup(cup)
+	if err := drawCupRound(&drawn); err != nil {
+		return nil, err
+	}
+	if err := db.SaveCupRound(&drawn, 0); err != nil {
+		return nil, err
+	}
+
+	*cup = drawn
+	return cupRoundTies(cup, cup.CurrentRound), nil
+}
+
+// drawCupRound adds the ties of the next round to the cup without storing them
+func drawCupRound(cup *models.Cup) error {
+	switch cup.Status {
+	case CupStatusCompleted:
+		return fmt.Errorf("%w: cup is already completed", ErrInvalidCup)
+	case CupStatusDrawn:
+		return fmt.Errorf("%w: round %d has not been played yet", ErrInvalidCup, cup.CurrentRound)
+	}
+
+	round := cup.CurrentRound + 1
+	var ties []models.CupTie
+	if cup.CurrentRound == 0 {
+		ties = drawFirstRound(cup.Entrants, cup.DrawMethod)
+	} else {
+		ties = drawNextRound(cupRoundWinners(cup, cup.CurrentRound), cup.DrawMethod)
+	}
+
+	for i := range ties {
+		ties[i].CupID = cup.ID
+		ties[i].Round = round
+	}
+
+	cup.CurrentRound = round
+	cup.Status = CupStatusDrawn
+	cup.Ties = append(cup.Ties, ties...)
+	return nil
+}
+
+// copyCup copies a cup so that a round can be drawn or played on the copy
+// and only kept once it has been stored
+func copyCup(cup *models.Cup) models.Cup {
+	copied := *cup
+	copied.Ties = append([]models.CupTie{}, cup.Ties...)
+	return copied
+}
+
+// cupRoundTies returns the ties of one round in bracket order
+func cupRoundTies(cup *models.Cup, round int) []models.CupTie {
+	var ties []models.CupTie
+	for _, tie := range cup.Ties {
+		if tie.Round == round {
+			ties = append(ties, tie)
+		}
+	}
+	return ties
+}
+
+// drawFirstRound pairs the entrants in standard bracket order (1 v 8, 4 v 5, ...).
+// Missing seeds in a non-power-of-two field become byes.
+func drawFirstRound(entrants []string, drawMethod string) []models.CupTie {
+	order := append([]string{}, entrants...)
+	if drawMethod == DrawRandom {
+		rand.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	}
+
+	seedTeam := func(seed int) string {
+		if seed <= len(order) {
+			return order[seed-1]
+		}
+		return ""
+	}
+
+	positions := bracketOrder(bracketSize(len(order)))
+	var ties []models.CupTie
+	for i := 0; i < len(positions); i += 2 {
+		home := seedTeam(positions[i])
+		away := seedTeam(positions[i+1])
+		tie := models.CupTie{Slot: i/2 + 1, HomeTeam: home, AwayTeam: away, Legs: []models.CupLeg{}}
+		if away == "" {
+			tie.Bye = true
+			tie.Played = true
+			tie.Winner = home
+		}
+		ties = append(ties, tie)
+	}
+	return ties
+}
+
+// drawNextRound pairs the previous round's winners, keeping bracket order for
+// seeded cups and drawing fresh pairings for random ones
+func drawNextRound(winners []string, drawMethod string) []models.CupTie {
+	if drawMethod == DrawRandom {
+		rand.Shuffle(len(winners), func(i, j int) { winners[i], winners[j] = winners[j], winners[i] })
+	}
+
+	var ties []models.CupTie
+	for i := 0; i+1 < len(winners); i += 2 {
+		ties = append(ties, models.CupTie{
+			Slot:     i/2 + 1,
+			HomeTeam: winners[i],
+			AwayTeam: winners[i+1],
+			Legs:     []models.CupLeg{},
+		})
+	}
+	return ties
+}
+
+// PlayCupRound simulates every unplayed tie of the current round
+func PlayCupRound(cup *models.Cup) ([]models.CupTie, error) {
+	played := copyCup(cup)
+	if err := playCupRound(&played); err != nil {
+		return nil, err
+	}
+	if err := db.SaveCupRound(&played, played.CurrentRound); err != nil {
+		return nil, err
+	}
+
+	*cup = played
+	return cupRoundTies(cup, cup.CurrentRound), nil
+}
+
+// playCupRound plays the current round of the cup without storing the results
+func playCupRound(cup *models.Cup) error {
+	if cup.Status != CupStatusDrawn {
+		return fmt.Errorf("%w: no drawn round waiting to be played", ErrInvalidCup)
+	}
+
+	teams, err := db.GetAllTeams()
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]*models.Team)
+	for i := range teams {
+		byName[teams[i].Name] = &teams[i]
+	}
+
+	for i := range cup.Ties {
+		tie := &cup.Ties[i]
+		if tie.Round != cup.CurrentRound || tie.Played {
+			continue
+		}
+		home, away := byName[tie.HomeTeam], byName[tie.AwayTeam]
+		if home == nil || away == nil {
+			return fmt.Errorf("team missing for tie %s vs %s", tie.HomeTeam, tie.AwayTeam)
+		}
+		playKnockoutTie(tie, cup.Legs, home, away)
+	}
+
+	if roundTies := cupRoundTies(cup, cup.CurrentRound); len(roundTies) == 1 {
+		cup.Status = CupStatusCompleted
+		cup.Champion = roundTies[0].Winner
+	} else {
+		cup.Status = CupStatusAwaitingDraw
+	}
+	return nil
+}
+
+// playKnockoutTie plays one or two legs and settles a level aggregate with
+// extra time and then penalties, both taken at the venue of the last leg
+func playKnockoutTie(tie *models.CupTie, legs int, home *models.Team, away *models.Team) {
+	homeGoals, awayGoals := simulateScore(home, away)
+	tie.Legs = []models.CupLeg{{
+		HomeTeam:  home.Name,
+		AwayTeam:  away.Name,
+		HomeGoals: homeGoals,
+		AwayGoals: awayGoals,
+	}}
+	aggregateHome, aggregateAway := homeGoals, awayGoals
+
+	if legs == 2 {
+		returnHome, returnAway := simulateScore(away, home)
+		tie.Legs = append(tie.Legs, models.CupLeg{
+			HomeTeam:  away.Name,
+			AwayTeam:  home.Name,
+			HomeGoals: returnHome,
+			AwayGoals: returnAway,
+		})
+		aggregateHome += returnAway
+		aggregateAway += returnHome
+	}
+
+	if aggregateHome == aggregateAway {
+		extraHome, extraAway := simulateExtraTime(home, away)
+		tie.ExtraTime = &models.ScoreLine{Home: extraHome, Away: extraAway}
+		aggregateHome += extraHome
+		aggregateAway += extraAway
+	}
+
+	if aggregateHome == aggregateAway {
+		penaltiesHome, penaltiesAway := simulatePenalties()
+		tie.Penalties = &models.ScoreLine{Home: penaltiesHome, Away: penaltiesAway}
+		aggregateHome += penaltiesHome
+		aggregateAway += penaltiesAway
+	}
+
+	if aggregateHome > aggregateAway {
+		tie.Winner = home.Name
+	} else {
+		tie.Winner = away.Name
+	}
+	tie.Played = true
+}
+
+// simulateExtraTime draws the goals of 30 minutes of extra time, a third of a normal match
+func simulateExtraTime(home *models.Team, away *models.Team) (int, int) {
+	return rand.Intn(home.Strength/45 + 1), rand.Intn(away.Strength/45 + 1)
+}
+
+// simulatePenalties runs a shootout of five kicks each followed by sudden death
+func simulatePenalties() (int, int) {
+	const conversionRate = 0.75
+	home, away := 0, 0
+
+	for kick := 0; kick < 5; kick++ {
+		if rand.Float64() < conversionRate {
+			home++
+		}
+		if rand.Float64() < conversionRate {
+			away++
+		}
+	}
+
+	for home == away {
+		if rand.Float64() < conversionRate {
+			home++
+		}
+		if rand.Float64() < conversionRate {
+			away++
+		}
+	}
+
+	return home, away
+}
+
+// CupBracket groups the drawn ties of a cup by round
+func CupBracket(cup *models.Cup) []CupRound {
+	rounds := []CupRound{}
+	for _, tie := range cup.Ties {
+		if len(rounds) == 0 || rounds[len(rounds)-1].Round != tie.Round {
+			rounds = append(rounds, CupRound{Round: tie.Round, Completed: true})
+		}
+		current := &rounds[len(rounds)-1]
+		current.Ties = append(current.Ties, tie)
+		if !tie.Played {
+			current.Completed = false
+		}
+	}
+
+	for i := range rounds {
+		rounds[i].Name = knockoutRoundName(len(rounds[i].Ties) * 2)
+	}
+	return rounds
+}
+
+// CupTotalRounds returns how many rounds the cup needs to produce a champion
+func CupTotalRounds(cup *models.Cup) int {
+	rounds := 0
+	for size := bracketSize(len(cup.Entrants)); size > 1; size /= 2 {
+		rounds++
+	}
+	return rounds
+}
+
+// cupRoundWinners returns the winners of a round in slot order
+func cupRoundWinners(cup *models.Cup, round int) []string {
+	var winners []string
+	for _, tie := range cup.Ties {
+		if tie.Round == round {
+			winners = append(winners, tie.Winner)
+		}
+	}
+	return winners
+}
+
+func knockoutRoundName(teams int) string {
+	switch teams {
+	case 2:
+		return "Final"
+	case 4:
+		return "Semi-finals"
+	case 8:
+		return "Quarter-finals"
+	default:
+		return fmt.Sprintf("Round of %d", teams)
+	}
+}
+
+// bracketSize returns the smallest power of two that fits the given number of teams
+func bracketSize(teams int) int {
+	size := 1
+	for size < teams {
+		size *= 2
+	}
+	return size
+}
+
+// bracketOrder lists seeds in bracket position order so that the top seeds
+// can only meet in the latest rounds, e.g. 1, 8, 4, 5, 2, 7, 3, 6 for 8 slots
+func bracketOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		next := make([]int, 0, len(order)*2)
+		for _, seed := range order {
+			next = append(next, seed, len(order)*2+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// playKnockoutBracket plays a seeded bracket through to its final without
+// storing it, returning every tie and the winner
+func playKnockoutBracket(seeds []string, legs int, teams map[string]*models.Team) ([]models.CupTie, string, error) {
+	if len(seeds) == 1 {
+		return []models.CupTie{}, seeds[0], nil
+	}
+
+	var played []models.CupTie
+	ties := drawFirstRound(seeds, DrawSeeded)
+	for round := 1; ; round++ {
+		var winners []string
+		for i := range ties {
+			ties[i].Round = round
+			if !ties[i].Played {
+				home, away := teams[ties[i].HomeTeam], teams[ties[i].AwayTeam]
+				if home == nil || away == nil {
+					return nil, "", fmt.Errorf("team missing for tie %s vs %s", ties[i].HomeTeam, ties[i].AwayTeam)
+				}
+				playKnockoutTie(&ties[i], legs, home, away)
+			}
+			winners = append(winners, ties[i].Winner)
+		}
+		played = append(played, ties...)
+
+		if len(winners) == 1 {
+			return played, winners[0], nil
+		}
+		ties = drawNextRound(winners, DrawSeeded)
+	}
+}
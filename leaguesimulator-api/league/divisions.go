@@ -0,0 +1,417 @@
+package league
+
+import (
+	"errors"
+	"fmt"
+
+	"leaguesimulator/db"
+	"leaguesimulator/models"
+)
+
+const (
+	MovementPromoted  = "promoted"
+	MovementRelegated = "relegated"
+)
+
+// ErrInvalidDivision is returned when a division request breaks the pyramid rules
+var ErrInvalidDivision = errors.New("invalid division operation")
+
+type DivisionTable struct {
+	Division  models.Division `json:"division"`
+	Season    int             `json:"season"`
+	Standings []TeamStanding  `json:"standings"`
+	Completed bool            `json:"completed"`
+}
+
+type PromotionPlayoff struct {
+	Division string          `json:"division"`
+	Ties     []models.CupTie `json:"ties"`
+	Winner   string          `json:"winner"`
+}
+
+type SeasonRollover struct {
+	CompletedSeason int                       `json:"completed_season"`
+	NewSeason       int                       `json:"new_season"`
+	Champions       map[string]string         `json:"champions"`
+	Movements       []models.DivisionMovement `json:"movements"`
+	Playoffs        []PromotionPlayoff        `json:"playoffs"`
+	Divisions       []models.Division         `json:"divisions"`
+}
+
+// CreateDivision adds a division to the pyramid for the current season
+func CreateDivision(name string, tier int, teamNames []string, promotion, relegation, playoff int) (*models.Division, error) {
+	if tier < 1 {
+		return nil, fmt.Errorf("%w: tier must be 1 or higher", ErrInvalidDivision)
+	}
+	if len(teamNames) < 2 {
+		return nil, fmt.Errorf("%w: a division needs at least 2 teams", ErrInvalidDivision)
+	}
+	if promotion < 0 || relegation < 0 || playoff < 0 {
+		return nil, fmt.Errorf("%w: promotion, relegation and playoff spots cannot be negative", ErrInvalidDivision)
+	}
+	if playoff == 1 {
+		return nil, fmt.Errorf("%w: a promotion playoff needs at least 2 teams", ErrInvalidDivision)
+	}
+	if promotion+playoff+relegation > len(teamNames) {
+		return nil, fmt.Errorf("%w: promotion, playoff and relegation places overlap", ErrInvalidDivision)
+	}
+
+	season, divisions, err := GetPyramid()
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := db.GetDivisionMatches(season)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) > 0 {
+		return nil, fmt.Errorf("%w: season %d is already under way", ErrInvalidDivision, season)
+	}
+
+	assigned := make(map[string]string)
+	for _, division := range divisions {
+		if division.Tier == tier {
+			return nil, fmt.Errorf("%w: tier %d already belongs to %s", ErrInvalidDivision, tier, division.Name)
+		}
+		for _, teamName := range division.Teams {
+			assigned[teamName] = division.Name
+		}
+	}
+
+	teams, err := db.GetAllTeams()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := selectTeams(teams, teamNames); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDivision, err)
+	}
+	for _, teamName := range teamNames {
+		if divisionName, ok := assigned[teamName]; ok {
+			return nil, fmt.Errorf("%w: %s already plays in %s", ErrInvalidDivision, teamName, divisionName)
+		}
+	}
+
+	division := models.Division{
+		Name:            name,
+		Tier:            tier,
+		PromotionSpots:  promotion,
+		RelegationSpots: relegation,
+		PlayoffSpots:    playoff,
+		Teams:           teamNames,
+	}
+
+	id, err := db.CreateDivision(season, division)
+	if err != nil {
+		return nil, err
+	}
+	division.ID = id
+
+	return &division, nil
+}
+
+// GetPyramid returns the current pyramid season and its divisions ordered by tier
+func GetPyramid() (int, []models.Division, error) {
+	season, err := db.GetLatestDivisionSeason()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	divisions, err := divisionsForSeason(season)
+	return season, divisions, err
+}
+
+// divisionsForSeason loads every division with the teams it had in the given season
+func divisionsForSeason(season int) ([]models.Division, error) {
+	divisions, err := db.GetAllDivisions()
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := db.GetDivisionMembers(season)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range divisions {
+		divisions[i].Teams = members[divisions[i].ID]
+		if divisions[i].Teams == nil {
+			divisions[i].Teams = []string{}
+		}
+	}
+	return divisions, nil
+}
+
+// PlayDivisionSeason plays a double round-robin in every division of the current season
+func PlayDivisionSeason() ([]DivisionTable, error) {
+	season, divisions, err := GetPyramid()
+	if err != nil {
+		return nil, err
+	}
+	if len(divisions) == 0 {
+		return nil, fmt.Errorf("%w: no divisions have been created", ErrInvalidDivision)
+	}
+
+	existing, err := db.GetDivisionMatches(season)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("%w: season %d has already been played", ErrInvalidDivision, season)
+	}
+
+	teams, err := db.GetAllTeams()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*models.Team)
+	for i := range teams {
+		byName[teams[i].Name] = &teams[i]
+	}
+
+	var matches []models.DivisionMatch
+	for _, division := range divisions {
+		for _, fixture := range doubleRoundRobin(division.Teams) {
+			home, away := byName[fixture.HomeTeam], byName[fixture.AwayTeam]
+			if home == nil || away == nil {
+				return nil, fmt.Errorf("team missing for match %s vs %s", fixture.HomeTeam, fixture.AwayTeam)
+			}
+
+			match := models.DivisionMatch{
+				Season:     season,
+				DivisionID: division.ID,
+				Week:       fixture.Week,
+				HomeTeam:   fixture.HomeTeam,
+				AwayTeam:   fixture.AwayTeam,
+			}
+			match.HomeGoals, match.AwayGoals = simulateScore(home, away)
+			matches = append(matches, match)
+		}
+	}
+	if err := db.SaveDivisionMatches(matches); err != nil {
+		return nil, err
+	}
+
+	return DivisionTables(season)
+}
+
+// DivisionTables builds the table of every division for a season
+func DivisionTables(season int) ([]DivisionTable, error) {
+	divisions, err := divisionsForSeason(season)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := db.GetDivisionMatches(season)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := []DivisionTable{}
+	for _, division := range divisions {
+		if len(division.Teams) == 0 {
+			continue
+		}
+
+		var played []models.Match
+		for _, match := range matches {
+			if match.DivisionID == division.ID {
+				played = append(played, models.Match{
+					Week:      match.Week,
+					HomeTeam:  match.HomeTeam,
+					AwayTeam:  match.AwayTeam,
+					HomeGoals: match.HomeGoals,
+					AwayGoals: match.AwayGoals,
+					Played:    true,
+				})
+			}
+		}
+
+		teamCount := len(division.Teams)
+		tables = append(tables, DivisionTable{
+			Division:  division,
+			Season:    season,
+			Standings: computeStandings(division.Teams, played),
+			Completed: len(played) == teamCount*(teamCount-1),
+		})
+	}
+	return tables, nil
+}
+
+// RolloverDivisions applies promotion and relegation to the finished season
+// and starts the next season with the updated divisions. Automatic places
+// are decided on the final table; playoff places go through a seeded
+// single-leg knockout whose winner takes the extra promotion spot.
+func RolloverDivisions() (*SeasonRollover, error) {
+	season, err := db.GetLatestDivisionSeason()
+	if err != nil {
+		return nil, err
+	}
+
+	// Teams would otherwise move straight past an empty tier
+	divisions, err := divisionsForSeason(season)
+	if err != nil {
+		return nil, err
+	}
+	for _, division := range divisions {
+		if len(division.Teams) == 0 {
+			return nil, fmt.Errorf("%w: %s has no teams in season %d", ErrInvalidDivision, division.Name, season)
+		}
+	}
+
+	tables, err := DivisionTables(season)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("%w: no divisions have been created", ErrInvalidDivision)
+	}
+	for _, table := range tables {
+		if !table.Completed {
+			return nil, fmt.Errorf("%w: %s has not finished season %d", ErrInvalidDivision, table.Division.Name, season)
+		}
+	}
+
+	// The places going up from a division must match the places coming down from the one above
+	for i := 1; i < len(tables); i++ {
+		upper, lower := tables[i-1].Division, tables[i].Division
+		goingUp := lower.PromotionSpots
+		if lower.PlayoffSpots > 0 {
+			goingUp++
+		}
+		if upper.RelegationSpots != goingUp {
+			return nil, fmt.Errorf("%w: %s relegates %d team(s) but %s promotes %d",
+				ErrInvalidDivision, upper.Name, upper.RelegationSpots, lower.Name, goingUp)
+		}
+	}
+
+	teams, err := db.GetAllTeams()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*models.Team)
+	for i := range teams {
+		byName[teams[i].Name] = &teams[i]
+	}
+
+	rollover := &SeasonRollover{
+		CompletedSeason: season,
+		NewSeason:       season + 1,
+		Champions:       make(map[string]string),
+		Movements:       []models.DivisionMovement{},
+		Playoffs:        []PromotionPlayoff{},
+	}
+
+	moving := make(map[string]bool)
+	for i, table := range tables {
+		division := table.Division
+		standings := table.Standings
+		rollover.Champions[division.Name] = standings[0].Name
+
+		if i > 0 {
+			above := tables[i-1].Division
+			for pos := 0; pos < division.PromotionSpots; pos++ {
+				reason := fmt.Sprintf("Promoted automatically after finishing %s in %s", ordinal(pos+1), division.Name)
+				if pos == 0 {
+					reason = fmt.Sprintf("Promoted as %s champions", division.Name)
+				}
+				rollover.Movements = append(rollover.Movements,
+					newMovement(season, standings[pos].Name, division, above, MovementPromoted, reason))
+				moving[standings[pos].Name] = true
+			}
+
+			if division.PlayoffSpots > 0 {
+				var seeds []string
+				positions := make(map[string]int)
+				for pos := division.PromotionSpots; pos < division.PromotionSpots+division.PlayoffSpots; pos++ {
+					seeds = append(seeds, standings[pos].Name)
+					positions[standings[pos].Name] = pos + 1
+				}
+
+				ties, winner, err := playKnockoutBracket(seeds, 1, byName)
+				if err != nil {
+					return nil, err
+				}
+				rollover.Playoffs = append(rollover.Playoffs, PromotionPlayoff{
+					Division: division.Name,
+					Ties:     ties,
+					Winner:   winner,
+				})
+
+				reason := fmt.Sprintf("Promoted via the playoff after finishing %s in %s", ordinal(positions[winner]), division.Name)
+				rollover.Movements = append(rollover.Movements,
+					newMovement(season, winner, division, above, MovementPromoted, reason))
+				moving[winner] = true
+			}
+		}
+
+		if i < len(tables)-1 {
+			below := tables[i+1].Division
+			for pos := len(standings) - division.RelegationSpots; pos < len(standings); pos++ {
+				reason := fmt.Sprintf("Relegated after finishing %s of %d in %s", ordinal(pos+1), len(standings), division.Name)
+				rollover.Movements = append(rollover.Movements,
+					newMovement(season, standings[pos].Name, division, below, MovementRelegated, reason))
+				moving[standings[pos].Name] = true
+			}
+		}
+	}
+
+	// Teams that did not move stay put; movers join their new division
+	nextMembers := make(map[int][]string)
+	for _, table := range tables {
+		for _, teamName := range table.Division.Teams {
+			if !moving[teamName] {
+				nextMembers[table.Division.ID] = append(nextMembers[table.Division.ID], teamName)
+			}
+		}
+	}
+	for _, movement := range rollover.Movements {
+		nextMembers[movement.ToDivisionID] = append(nextMembers[movement.ToDivisionID], movement.TeamName)
+	}
+
+	if err := db.SaveDivisionRollover(season+1, nextMembers, rollover.Movements); err != nil {
+		return nil, err
+	}
+
+	rollover.Divisions, err = divisionsForSeason(season + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return rollover, nil
+}
+
+// GetDivisionMovements returns the moves decided at the end of a season (every season when 0)
+func GetDivisionMovements(season int) ([]models.DivisionMovement, error) {
+	return db.GetDivisionMovements(season)
+}
+
+func newMovement(season int, teamName string, from, to models.Division, movement, reason string) models.DivisionMovement {
+	return models.DivisionMovement{
+		Season:         season,
+		TeamName:       teamName,
+		FromDivisionID: from.ID,
+		FromDivision:   from.Name,
+		ToDivisionID:   to.ID,
+		ToDivision:     to.Name,
+		Movement:       movement,
+		Reason:         reason,
+	}
+}
+
+// ordinal formats a league position as 1st, 2nd, 3rd, 4th, ...
+func ordinal(position int) string {
+	suffix := "th"
+	switch position % 10 {
+	case 1:
+		suffix = "st"
+	case 2:
+		suffix = "nd"
+	case 3:
+		suffix = "rd"
+	}
+	if position%100 >= 11 && position%100 <= 13 {
+		suffix = "th"
+	}
+	return fmt.Sprintf("%d%s", position, suffix)
+}
@@ -0,0 +1,155 @@
+package league
+
+import (
+	"errors"
+	"fmt"
+
+	"leaguesimulator/models"
+)
+
+// recentMeetings is the number of meetings shown in the head-to-head form guide
+const recentMeetings = 5
+
+// ErrInvalidHeadToHead is returned when a head-to-head filter makes no sense
+var ErrInvalidHeadToHead = errors.New("invalid head-to-head request")
+
+// HeadToHeadFilter narrows a head-to-head to a range of seasons and to
+// meetings where the first team was at home or away. Zero values mean no limit.
+type HeadToHeadFilter struct {
+	FromSeason int    `json:"from_season,omitempty"`
+	ToSeason   int    `json:"to_season,omitempty"`
+	Venue      string `json:"venue,omitempty"`
+}
+
+// HeadToHeadRecord tallies meetings from the first team's point of view
+type HeadToHeadRecord struct {
+	MatchesPlayed int `json:"matches_played"`
+	Team1Wins     int `json:"team1_wins"`
+	Team2Wins     int `json:"team2_wins"`
+	Draws         int `json:"draws"`
+	Team1Goals    int `json:"team1_goals"`
+	Team2Goals    int `json:"team2_goals"`
+}
+
+// HeadToHeadSeason is the record of one season's meetings
+type HeadToHeadSeason struct {
+	Season int `json:"season"`
+	HeadToHeadRecord
+}
+
+// HeadToHead is the all-time record between two teams
+type HeadToHead struct {
+	Team1       string                   `json:"team1"`
+	Team2       string                   `json:"team2"`
+	Filter      HeadToHeadFilter         `json:"filter"`
+	Record      HeadToHeadRecord         `json:"record"`
+	Team1AtHome HeadToHeadRecord         `json:"team1_at_home"`
+	Team2AtHome HeadToHeadRecord         `json:"team2_at_home"`
+	Seasons     []HeadToHeadSeason       `json:"seasons"`
+	Team1Best   *models.HistoricalMatch  `json:"team1_biggest_win"`
+	Team2Best   *models.HistoricalMatch  `json:"team2_biggest_win"`
+	RecentForm  []string                 `json:"recent_form"`
+	Matches     []models.HistoricalMatch `json:"matches"`
+}
+
+// add counts one meeting; team1Goals and team2Goals are from the first team's side
+func (r *HeadToHeadRecord) add(team1Goals, team2Goals int) {
+	r.MatchesPlayed++
+	r.Team1Goals += team1Goals
+	r.Team2Goals += team2Goals
+	switch {
+	case team1Goals > team2Goals:
+		r.Team1Wins++
+	case team1Goals < team2Goals:
+		r.Team2Wins++
+	default:
+		r.Draws++
+	}
+}
+
+// GetHeadToHead builds the record between two teams over every season,
+// narrowed by the filter
+func (lm *LeagueManager) GetHeadToHead(team1, team2 string, filter HeadToHeadFilter) (*HeadToHead, error) {
+	if team1 == team2 {
+		return nil, fmt.Errorf("%w: a team cannot meet itself", ErrInvalidHeadToHead)
+	}
+	if filter.FromSeason != 0 && filter.ToSeason != 0 && filter.FromSeason > filter.ToSeason {
+		return nil, fmt.Errorf("%w: from_season %d is after to_season %d", ErrInvalidHeadToHead, filter.FromSeason, filter.ToSeason)
+	}
+	switch filter.Venue {
+	case "", VenueHome, VenueAway:
+	default:
+		return nil, fmt.Errorf("%w: venue must be %q or %q", ErrInvalidHeadToHead, VenueHome, VenueAway)
+	}
+
+	matches, err := lm.archivedMatches()
+	if err != nil {
+		return nil, err
+	}
+
+	h2h := &HeadToHead{
+		Team1:      team1,
+		Team2:      team2,
+		Filter:     filter,
+		Seasons:    []HeadToHeadSeason{},
+		RecentForm: []string{},
+		Matches:    []models.HistoricalMatch{},
+	}
+	for i, match := range matches {
+		team1Home := match.HomeTeam == team1 && match.AwayTeam == team2
+		team2Home := match.HomeTeam == team2 && match.AwayTeam == team1
+		if !team1Home && !team2Home {
+			continue
+		}
+		if (filter.FromSeason != 0 && match.Season < filter.FromSeason) ||
+			(filter.ToSeason != 0 && match.Season > filter.ToSeason) ||
+			(filter.Venue == VenueHome && !team1Home) ||
+			(filter.Venue == VenueAway && team1Home) {
+			continue
+		}
+
+		team1Goals, team2Goals := match.HomeGoals, match.AwayGoals
+		if team2Home {
+			team1Goals, team2Goals = team2Goals, team1Goals
+		}
+
+		h2h.Matches = append(h2h.Matches, match)
+		h2h.Record.add(team1Goals, team2Goals)
+		if team1Home {
+			h2h.Team1AtHome.add(team1Goals, team2Goals)
+		} else {
+			h2h.Team2AtHome.add(team1Goals, team2Goals)
+		}
+
+		if last := len(h2h.Seasons) - 1; last < 0 || h2h.Seasons[last].Season != match.Season {
+			h2h.Seasons = append(h2h.Seasons, HeadToHeadSeason{Season: match.Season})
+		}
+		h2h.Seasons[len(h2h.Seasons)-1].add(team1Goals, team2Goals)
+
+		margin := team1Goals - team2Goals
+		if margin > 0 && (h2h.Team1Best == nil || margin > absMargin(*h2h.Team1Best)) {
+			h2h.Team1Best = &matches[i]
+		}
+		if margin < 0 && (h2h.Team2Best == nil || -margin > absMargin(*h2h.Team2Best)) {
+			h2h.Team2Best = &matches[i]
+		}
+	}
+
+	// Form guide from the first team's side, most recent meeting first
+	for i := len(h2h.Matches) - 1; i >= 0 && len(h2h.RecentForm) < recentMeetings; i-- {
+		match := h2h.Matches[i]
+		team1Goals, team2Goals := match.HomeGoals, match.AwayGoals
+		if match.HomeTeam != team1 {
+			team1Goals, team2Goals = team2Goals, team1Goals
+		}
+		switch {
+		case team1Goals > team2Goals:
+			h2h.RecentForm = append(h2h.RecentForm, "W")
+		case team1Goals < team2Goals:
+			h2h.RecentForm = append(h2h.RecentForm, "L")
+		default:
+			h2h.RecentForm = append(h2h.RecentForm, "D")
+		}
+	}
+	return h2h, nil
+}
@@ -0,0 +1,79 @@
+package league
+
+import (
+	"leaguesimulator/models"
+)
+
+// WeekStandings is the league table as it stood after one week
+type WeekStandings struct {
+	Week      int            `json:"week"`
+	Standings []TeamStanding `json:"standings"`
+}
+
+// TeamWeek is a team's place in the table after one week
+type TeamWeek struct {
+	Week     int `json:"week"`
+	Position int `json:"position"`
+	Points   int `json:"points"`
+	GoalDiff int `json:"goal_diff"`
+}
+
+// TeamTimeline is a team's position, points and goal difference week by week
+type TeamTimeline struct {
+	Team  string     `json:"team"`
+	Weeks []TeamWeek `json:"weeks"`
+}
+
+// StandingsHistory is the table after every played week together with each
+// team's timeline
+type StandingsHistory struct {
+	Weeks     []WeekStandings `json:"weeks"`
+	Timelines []TeamTimeline  `json:"timelines"`
+}
+
+// GetStandingsHistory rebuilds the table after every week that has results
+func (lm *LeagueManager) GetStandingsHistory() StandingsHistory {
+	lastWeek := 0
+	for _, match := range lm.Matches {
+		if match.Played && match.Week > lastWeek {
+			lastWeek = match.Week
+		}
+	}
+
+	teamNames := lm.teamNames()
+	history := StandingsHistory{Weeks: []WeekStandings{}, Timelines: []TeamTimeline{}}
+	timelines := make(map[string]*TeamTimeline)
+	for _, name := range teamNames {
+		timelines[name] = &TeamTimeline{Team: name, Weeks: []TeamWeek{}}
+	}
+
+	for week := 1; week <= lastWeek; week++ {
+		table := computeStandings(teamNames, matchesUpToWeek(lm.Matches, week))
+		history.Weeks = append(history.Weeks, WeekStandings{Week: week, Standings: table})
+		for position, standing := range table {
+			timeline := timelines[standing.Name]
+			timeline.Weeks = append(timeline.Weeks, TeamWeek{
+				Week:     week,
+				Position: position + 1,
+				Points:   standing.Points,
+				GoalDiff: standing.GoalDiff,
+			})
+		}
+	}
+
+	for _, name := range teamNames {
+		history.Timelines = append(history.Timelines, *timelines[name])
+	}
+	return history
+}
+
+// matchesUpToWeek returns the matches scheduled in or before the given week
+func matchesUpToWeek(matches []models.Match, week int) []models.Match {
+	selected := []models.Match{}
+	for _, match := range matches {
+		if match.Week <= week {
+			selected = append(selected, match)
+		}
+	}
+	return selected
+}
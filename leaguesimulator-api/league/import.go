@@ -0,0 +1,172 @@
+package league
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"leaguesimulator/db"
+	"leaguesimulator/importer"
+	"leaguesimulator/models"
+)
+
+// ErrRemovesHistory is returned when replacing the teams would delete the
+// stored history of the teams that are left out and the caller did not agree to it
+var ErrRemovesHistory = errors.New("removing teams would delete their stored history")
+
+// ImportOptions control an import. RemoveTeams allows teams that are not in
+// the import to be removed together with their past seasons, aliases and
+// competition entries.
+type ImportOptions struct {
+	DryRun      bool `json:"dry_run"`
+	RemoveTeams bool `json:"remove_teams"`
+}
+
+// ImportSummary describes what an import loaded, or would load on a dry run
+type ImportSummary struct {
+	DryRun            bool                  `json:"dry_run"`
+	Teams             int                   `json:"teams"`
+	Fixtures          int                   `json:"fixtures"`
+	Played            int                   `json:"played"`
+	GeneratedFixtures bool                  `json:"generated_fixtures"`
+	TotalWeeks        int                   `json:"total_weeks"`
+	Violations        []ConstraintViolation `json:"violations,omitempty"`
+	RemovedTeams      *TeamRemoval          `json:"removed_teams,omitempty"`
+}
+
+// TeamRemoval lists the teams that replacing the league drops and, per
+// table, the stored rows that are deleted with them
+type TeamRemoval struct {
+	Teams       []string       `json:"teams"`
+	DeletedRows map[string]int `json:"deleted_rows"`
+}
+
+// teamRemoval works out what replacing the league teams with teams removes.
+// It returns nil when every current team is kept.
+func (lm *LeagueManager) teamRemoval(teams []models.Team) (*TeamRemoval, error) {
+	kept := make(map[string]bool)
+	for _, team := range teams {
+		kept[team.Name] = true
+	}
+	var removed []string
+	for _, team := range lm.Teams {
+		if !kept[team.Name] {
+			removed = append(removed, team.Name)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	counts, err := db.CountTeamHistory(removed)
+	if err != nil {
+		return nil, err
+	}
+	return &TeamRemoval{Teams: removed, DeletedRows: counts}, nil
+}
+
+// check refuses a removal that deletes stored rows unless allowed
+func (removal *TeamRemoval) check(allowed bool) error {
+	if removal == nil || allowed || len(removal.DeletedRows) == 0 {
+		return nil
+	}
+	var tables []string
+	for table, count := range removal.DeletedRows {
+		tables = append(tables, fmt.Sprintf("%d %s", count, table))
+	}
+	sort.Strings(tables)
+	return fmt.Errorf("%w: removing %s deletes %s; keep the teams or set remove_teams",
+		ErrRemovesHistory, strings.Join(removal.Teams, ", "), strings.Join(tables, ", "))
+}
+
+// ImportLeague replaces the league's teams and matches with imported ones in
+// a single transaction. Without fixtures a schedule is generated for the new
+// teams. Teams that keep their name keep their venue, and any playoff bracket
+// and the result audit log of the replaced matches are dropped. Teams that are not in the import are removed, which deletes
+// their past seasons, so an import that drops teams with stored history is
+// refused unless RemoveTeams is set; a dry run reports what would be deleted.
+func (lm *LeagueManager) ImportLeague(teams []models.Team, fixtures []models.Match, options ImportOptions) (*ImportSummary, error) {
+	if len(teams) < 2 {
+		return nil, fmt.Errorf("%w: at least two teams are required", importer.ErrInvalidImport)
+	}
+	removal, err := lm.teamRemoval(teams)
+	if err != nil {
+		return nil, err
+	}
+
+	venues := make(map[string]int)
+	for _, team := range lm.Teams {
+		venues[team.Name] = team.VenueID
+	}
+	imported := lm.copyState()
+	imported.Teams = make([]models.Team, len(teams))
+	for i, team := range teams {
+		team.VenueID = venues[team.Name]
+		imported.Teams[i] = team
+	}
+
+	summary := &ImportSummary{DryRun: options.DryRun, Teams: len(teams), RemovedTeams: removal}
+	if len(fixtures) == 0 {
+		fixtures, summary.Violations = imported.buildFixtures()
+		summary.GeneratedFixtures = true
+	} else {
+		// Date the imported fixtures that came without a kick-off
+		fixtures = append([]models.Match{}, fixtures...)
+		dated := append([]models.Match{}, fixtures...)
+		imported.scheduleFixtures(dated)
+		for i := range fixtures {
+			if fixtures[i].KickOff == nil {
+				fixtures[i].KickOff = dated[i].KickOff
+			}
+			if team := imported.findTeam(fixtures[i].HomeTeam); team != nil {
+				fixtures[i].VenueID = team.VenueID
+			}
+		}
+	}
+
+	summary.Fixtures = len(fixtures)
+	for _, fixture := range fixtures {
+		if fixture.Played {
+			summary.Played++
+		}
+		if fixture.Week > summary.TotalWeeks {
+			summary.TotalWeeks = fixture.Week
+		}
+	}
+	if options.DryRun {
+		return summary, nil
+	}
+	if err := removal.check(options.RemoveTeams); err != nil {
+		return nil, err
+	}
+
+	settings := map[string]string{settingPlayoffCup: ""}
+	if err := db.RestoreLeagueState(db.CurrentSeason, imported.Teams, fixtures, nil, nil, settings); err != nil {
+		return nil, err
+	}
+
+	lm.InitLeague()
+	lm.recalculateTeamStats()
+	lm.Week = lm.completedWeeks()
+	return summary, nil
+}
+
+// ImportFiles parses a teams file and an optional fixtures file (nil when
+// absent) in the given formats and imports them. Row errors are returned as an
+// *importer.ValidationError.
+func (lm *LeagueManager) ImportFiles(teamsFile io.Reader, teamsFormat string, fixturesFile io.Reader, fixturesFormat string, options ImportOptions) (*ImportSummary, error) {
+	teams, err := importer.ParseTeams(teamsFile, teamsFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixtures []models.Match
+	if fixturesFile != nil {
+		if fixtures, err = importer.ParseFixtures(fixturesFile, fixturesFormat, teams); err != nil {
+			return nil, err
+		}
+	}
+	return lm.ImportLeague(teams, fixtures, options)
+}
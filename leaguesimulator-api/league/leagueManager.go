@@ -1,6 +1,8 @@
 package league
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
@@ -12,18 +14,29 @@ import (
 )
 
 type LeagueManager struct {
-	Teams     []models.Team
-	Matches   []models.Match
-	Week      int
-	Standings []TeamStanding
+	Teams        []models.Team
+	Matches      []models.Match
+	Week         int
+	Standings    []TeamStanding
+	Playoffs     PlayoffConfig
+	PlayoffCupID int
+	Calendar     CalendarConfig
+	Constraints  SchedulingConstraints
+	Mode         string
+	Seed         int64
 }
 
+// ErrMatchNotFound is returned when no stored match has the requested ID
+var ErrMatchNotFound = errors.New("match not found")
+
 type MatchView struct {
-	Week   int    `json:"week"`
-	Team1  string `json:"team1"`
-	Team2  string `json:"team2"`
-	Score1 int    `json:"score1"`
-	Score2 int    `json:"score2"`
+	Week    int        `json:"week"`
+	Team1   string     `json:"team1"`
+	Team2   string     `json:"team2"`
+	Score1  int        `json:"score1"`
+	Score2  int        `json:"score2"`
+	KickOff *time.Time `json:"kick_off,omitempty"`
+	Venue   string     `json:"venue,omitempty"`
 }
 
 type TeamStanding struct {
@@ -36,6 +49,7 @@ type TeamStanding struct {
 	GoalsAgainst int    `json:"goals_against"`
 	GoalDiff     int    `json:"goal_diff"`
 	Points       int    `json:"points"`
+	GamesInHand  int    `json:"games_in_hand"`
 }
 
 // InitLeague initializes teams and resets stats
@@ -54,33 +68,66 @@ func (lm *LeagueManager) InitLeague() {
 
 	lm.Teams = teams
 	lm.Week = 0
+	lm.loadCalendarSettings()
+	lm.loadSchedulingSettings()
+	lm.loadLeagueMode()
+	lm.loadRandomSeed()
+	lm.loadCurrentSeason()
 
 	// Load existing matches from database
 	matches, err := db.GetAllMatches()
+	if err == nil && len(matches) == 0 {
+		// First run: store the whole season's fixtures up front
+		if _, err := lm.createFixtures(); err != nil {
+			log.Printf("Failed to create fixtures: %v", err)
+		}
+		matches, err = db.GetAllMatches()
+	}
 	if err == nil {
 		lm.Matches = matches
-		// Calculate current week from existing matches
-		maxWeek := 0
-		for _, match := range matches {
-			if match.Week > maxWeek {
-				maxWeek = match.Week
-			}
-		}
-		lm.Week = maxWeek
+		// Calculate current week from the played matches
+		lm.Week = lm.completedWeeks()
 	} else {
 		lm.Matches = []models.Match{}
 	}
 
 	lm.Standings = []TeamStanding{}
 	lm.updateStandings()
+	lm.loadPlayoffSettings()
+}
+
+// createFixtures replaces the stored matches, in one transaction, with a
+// double round-robin for the current teams that meets the scheduling
+// constraints as far as possible, dated from the calendar
+func (lm *LeagueManager) createFixtures() (ScheduleReport, error) {
+	fixtures, violations := lm.buildFixtures()
+
+	totalWeeks := 0
+	for _, fixture := range fixtures {
+		if fixture.Week > totalWeeks {
+			totalWeeks = fixture.Week
+		}
+	}
+	if err := db.ReplaceMatches(fixtures); err != nil {
+		return ScheduleReport{}, err
+	}
+	return lm.newScheduleReport(violations, totalWeeks), nil
+}
+
+// buildFixtures solves the schedule for the current teams and dates it from
+// the calendar without storing it
+func (lm *LeagueManager) buildFixtures() ([]models.Match, []ConstraintViolation) {
+	fixtures, violations := lm.solveSchedule(lm.teamNames())
+	for _, violation := range violations {
+		log.Printf("Scheduling constraint not met: %s", violation.Detail)
+	}
+	lm.scheduleFixtures(fixtures)
+	return fixtures, violations
 }
 
 // playMatch simulates a match between home and away teams, updates their stats, returns the match record
 func (lm *LeagueManager) playMatch(week int, home *models.Team, away *models.Team) models.Match {
-	rand.Seed(time.Now().UnixNano())
-
-	homeGoals := rand.Intn(home.Strength/15 + 1)
-	awayGoals := rand.Intn(away.Strength/15 + 1)
+	homeGoals, awayGoals := drawScore(lm.matchRandom(week, home.Name, away.Name), home, away)
 
 	if homeGoals > awayGoals {
 		home.Points += 3
@@ -126,97 +173,224 @@ func (lm *LeagueManager) playMatch(week int, home *models.Team, away *models.Tea
 	return match
 }
 
-// PlayNextWeek simulates the matches of the next week, updates matches and standings
+// simulateScore draws a full-time score for home against away from their strengths
+func simulateScore(home *models.Team, away *models.Team) (int, int) {
+	return drawScore(rand.Intn, home, away)
+}
+
+// drawScore is simulateScore with an explicit source of random numbers, so
+// simulations can run on their own generator
+func drawScore(intn func(int) int, home *models.Team, away *models.Team) (int, int) {
+	homeGoals := intn(goalOutcomes(home))
+	awayGoals := intn(goalOutcomes(away))
+	return homeGoals, awayGoals
+}
+
+// goalOutcomes is the number of equally likely goal counts for a team in the
+// simulator: zero up to strength/15
+func goalOutcomes(team *models.Team) int {
+	return team.Strength/15 + 1
+}
+
+// SimulatedScoreProbabilities returns the exact score distribution drawScore
+// uses for home against away
+func (lm *LeagueManager) SimulatedScoreProbabilities(home, away string) ([][]float64, error) {
+	homeTeam, awayTeam := lm.findTeam(home), lm.findTeam(away)
+	if homeTeam == nil || awayTeam == nil {
+		return nil, fmt.Errorf("%w: %s and %s must both be league teams", ErrInvalidRatings, home, away)
+	}
+	homeGoals, awayGoals := goalOutcomes(homeTeam), goalOutcomes(awayTeam)
+	probabilities := make([][]float64, homeGoals)
+	for h := range probabilities {
+		probabilities[h] = make([]float64, awayGoals)
+		for a := range probabilities[h] {
+			probabilities[h][a] = 1 / float64(homeGoals*awayGoals)
+		}
+	}
+	return probabilities, nil
+}
+
+// PlayNextWeek simulates the fixtures of the next week, updates matches and
+// standings. Fixtures whose result was entered by hand are left as they are.
+// It does nothing in manual mode.
 func (lm *LeagueManager) PlayNextWeek() []MatchView {
-	if lm.Week >= 6 { // 6 rounds for 4 teams double round robin
+	if lm.Mode == LeagueModeManual || lm.Week >= lm.TotalWeeks() {
 		return nil
 	}
 
-	matchups := [][][]int{
-		{{0, 1}, {2, 3}}, // Week 1
-		{{0, 2}, {1, 3}}, // Week 2
-		{{0, 3}, {1, 2}}, // Week 3
-		{{1, 0}, {3, 2}}, // Week 4 (reverse)
-		{{2, 0}, {3, 1}}, // Week 5
-		{{3, 0}, {2, 1}}, // Week 6
-	}
+	week := lm.Week + 1
+	playedMatches := []MatchView{}
+
+	for i := range lm.Matches {
+		fixture := &lm.Matches[i]
+		if fixture.Week != week || fixture.Played || fixture.Postponed {
+			continue
+		}
 
-	weekMatchups := matchups[lm.Week]
-	var playedMatches []MatchView
+		home, away := lm.findTeam(fixture.HomeTeam), lm.findTeam(fixture.AwayTeam)
+		if home == nil || away == nil {
+			continue
+		}
 
-	for _, m := range weekMatchups {
-		match := lm.playMatch(lm.Week+1, &lm.Teams[m[0]], &lm.Teams[m[1]])
-		_ = db.SaveMatch(match)
-		lm.Matches = append(lm.Matches, match)
+		result := lm.playMatch(week, home, away)
+		fixture.HomeGoals = result.HomeGoals
+		fixture.AwayGoals = result.AwayGoals
+		fixture.Played = true
+		_ = db.UpdateMatchByID(*fixture)
 
-		playedMatches = append(playedMatches, MatchView{
-			Week:   match.Week,
-			Team1:  match.HomeTeam,
-			Team2:  match.AwayTeam,
-			Score1: match.HomeGoals,
-			Score2: match.AwayGoals,
-		})
+		playedMatches = append(playedMatches, newMatchView(*fixture))
 	}
 
 	lm.Week++
+	// Later weeks may already be complete from results entered by hand
+	if completed := lm.completedWeeks(); completed > lm.Week {
+		lm.Week = completed
+	}
 	lm.updateStandings()
+
+	lm.checkSeasonEnd()
+
 	return playedMatches
 }
 
+// TotalWeeks returns the number of weeks in the regular season
+func (lm *LeagueManager) TotalWeeks() int {
+	weeks := 0
+	for _, m := range lm.Matches {
+		if m.Week > weeks {
+			weeks = m.Week
+		}
+	}
+	return weeks
+}
+
+// findTeam returns the team with the given name, or nil
+func (lm *LeagueManager) findTeam(name string) *models.Team {
+	for i := range lm.Teams {
+		if lm.Teams[i].Name == name {
+			return &lm.Teams[i]
+		}
+	}
+	return nil
+}
+
+// findMatch returns the stored match with the given database ID, or nil
+func (lm *LeagueManager) findMatch(matchID int) *models.Match {
+	for i := range lm.Matches {
+		if lm.Matches[i].ID == matchID {
+			return &lm.Matches[i]
+		}
+	}
+	return nil
+}
+
+func newMatchView(m models.Match) MatchView {
+	return MatchView{
+		Week:    m.Week,
+		Team1:   m.HomeTeam,
+		Team2:   m.AwayTeam,
+		Score1:  m.HomeGoals,
+		Score2:  m.AwayGoals,
+		KickOff: m.KickOff,
+		Venue:   m.Venue,
+	}
+}
+
 // updateStandings recalculates the league table from matches
 func (lm *LeagueManager) updateStandings() {
-	standings := make(map[string]*TeamStanding)
+	lm.Standings = computeStandings(lm.teamNames(), lm.Matches)
+}
 
+// teamNames returns the names of the league's teams
+func (lm *LeagueManager) teamNames() []string {
+	teamNames := make([]string, 0, len(lm.Teams))
 	for _, t := range lm.Teams {
-		standings[t.Name] = &TeamStanding{Name: t.Name}
+		teamNames = append(teamNames, t.Name)
 	}
+	return teamNames
+}
 
-	for _, m := range lm.Matches {
-		home := standings[m.HomeTeam]
-		away := standings[m.AwayTeam]
+// computeStandings builds a sorted table for the given teams from their matches
+func computeStandings(teamNames []string, matches []models.Match) []TeamStanding {
+	return computeTable(teamNames, matches, func(int, string) bool { return true })
+}
 
-		home.Played++
-		away.Played++
+// computeTable builds a sorted table counting, for each played match, only
+// the sides for which include reports true
+func computeTable(teamNames []string, matches []models.Match, include func(i int, team string) bool) []TeamStanding {
+	standings := make(map[string]*TeamStanding)
 
-		home.GoalsFor += m.HomeGoals
-		home.GoalsAgainst += m.AwayGoals
-		away.GoalsFor += m.AwayGoals
-		away.GoalsAgainst += m.HomeGoals
+	for _, name := range teamNames {
+		standings[name] = &TeamStanding{Name: name}
+	}
 
-		if m.HomeGoals > m.AwayGoals {
-			home.Won++
-			away.Lost++
-			home.Points += 3
-		} else if m.AwayGoals > m.HomeGoals {
-			away.Won++
-			home.Lost++
-			away.Points += 3
-		} else {
-			home.Drawn++
-			away.Drawn++
-			home.Points++
-			away.Points++
+	for i, m := range matches {
+		home := standings[m.HomeTeam]
+		away := standings[m.AwayTeam]
+		if home == nil || away == nil || !m.Played {
+			continue
+		}
+
+		if include(i, m.HomeTeam) {
+			home.addResult(m.HomeGoals, m.AwayGoals)
+		}
+		if include(i, m.AwayTeam) {
+			away.addResult(m.AwayGoals, m.HomeGoals)
 		}
 	}
 
+	mostPlayed := 0
 	for _, s := range standings {
 		s.GoalDiff = s.GoalsFor - s.GoalsAgainst
+		if s.Played > mostPlayed {
+			mostPlayed = s.Played
+		}
+	}
+	// Postponed fixtures leave some teams with games in hand
+	for _, s := range standings {
+		s.GamesInHand = mostPlayed - s.Played
 	}
 
 	// Convert to slice
-	lm.Standings = []TeamStanding{}
+	table := []TeamStanding{}
 	for _, s := range standings {
-		lm.Standings = append(lm.Standings, *s)
+		table = append(table, *s)
+	}
+
+	sortStandings(table)
+	return table
+}
+
+// addResult counts one match from the team's point of view
+func (s *TeamStanding) addResult(scored, conceded int) {
+	s.Played++
+	s.GoalsFor += scored
+	s.GoalsAgainst += conceded
+
+	if scored > conceded {
+		s.Won++
+		s.Points += 3
+	} else if scored < conceded {
+		s.Lost++
+	} else {
+		s.Drawn++
+		s.Points++
 	}
+}
 
-	sort.Slice(lm.Standings, func(i, j int) bool {
-		if lm.Standings[i].Points != lm.Standings[j].Points {
-			return lm.Standings[i].Points > lm.Standings[j].Points
+// sortStandings orders a table by points, goal difference and goals scored
+func sortStandings(table []TeamStanding) {
+	sort.Slice(table, func(i, j int) bool {
+		if table[i].Points != table[j].Points {
+			return table[i].Points > table[j].Points
 		}
-		if lm.Standings[i].GoalDiff != lm.Standings[j].GoalDiff {
-			return lm.Standings[i].GoalDiff > lm.Standings[j].GoalDiff
+		if table[i].GoalDiff != table[j].GoalDiff {
+			return table[i].GoalDiff > table[j].GoalDiff
 		}
-		return lm.Standings[i].GoalsFor > lm.Standings[j].GoalsFor
+		if table[i].GoalsFor != table[j].GoalsFor {
+			return table[i].GoalsFor > table[j].GoalsFor
+		}
+		return table[i].Name < table[j].Name
 	})
 }
 
@@ -241,46 +415,33 @@ func (lm *LeagueManager) GetMatches() []models.Match {
 	return lm.Matches
 }
 
-// GetFutureFixtures returns matches not yet played (simple hardcoded fixture list)
+// GetFutureFixtures returns the stored fixtures that have not been played yet,
+// leaving out postponed ones that still need a new date
 func (lm *LeagueManager) GetFutureFixtures() []MatchView {
 	var fixtures []MatchView
-	allMatchups := [][][]string{
-		{{"Lions", "Tigers"}, {"Bears", "Wolves"}},
-		{{"Lions", "Bears"}, {"Tigers", "Wolves"}},
-		{{"Lions", "Wolves"}, {"Tigers", "Bears"}},
-		{{"Tigers", "Lions"}, {"Wolves", "Bears"}},
-		{{"Bears", "Lions"}, {"Wolves", "Tigers"}},
-		{{"Wolves", "Lions"}, {"Bears", "Tigers"}},
-	}
-
-	for w := lm.Week; w < len(allMatchups); w++ {
-		for _, m := range allMatchups[w] {
-			fixtures = append(fixtures, MatchView{
-				Week:  w + 1,
-				Team1: m[0],
-				Team2: m[1],
-			})
+	for _, m := range lm.Matches {
+		if !m.Played && !m.Postponed {
+			fixtures = append(fixtures, newMatchView(m))
 		}
 	}
-
 	return fixtures
 }
 
 // EditMatchResult edits a played match and recalculates stats
-func (lm *LeagueManager) EditMatchResult(week int, team1, team2 string, score1, score2 int) bool {
+func (lm *LeagueManager) EditMatchResult(week int, team1, team2 string, score1, score2 int, reason, actor string) bool {
 	for i, m := range lm.Matches {
-		if m.Week == week &&
+		if m.Played && m.Week == week &&
 			((m.HomeTeam == team1 && m.AwayTeam == team2) || (m.HomeTeam == team2 && m.AwayTeam == team1)) {
-			if m.HomeTeam == team1 {
-				lm.Matches[i].HomeGoals = score1
-				lm.Matches[i].AwayGoals = score2
-			} else {
-				lm.Matches[i].HomeGoals = score2
-				lm.Matches[i].AwayGoals = score1
+			homeGoals, awayGoals := score1, score2
+			if m.HomeTeam != team1 {
+				homeGoals, awayGoals = score2, score1
 			}
 
-			// Update in database
-			_ = db.UpdateMatch(lm.Matches[i])
+			// Update in database and the audit log
+			if err := lm.changeResult(&lm.Matches[i], homeGoals, awayGoals, reason, actor, 0, nil); err != nil {
+				log.Printf("Failed to edit match result: %v", err)
+				return false
+			}
 
 			lm.recalculateTeamStats()
 			return true
@@ -306,6 +467,10 @@ func (lm *LeagueManager) recalculateTeamStats() {
 	}
 
 	for _, m := range lm.Matches {
+		if !m.Played {
+			continue
+		}
+
 		var home, away *models.Team
 		for i := range lm.Teams {
 			if lm.Teams[i].Name == m.HomeTeam {
@@ -349,17 +514,21 @@ func (lm *LeagueManager) recalculateTeamStats() {
 	lm.updateStandings()
 }
 
-// ResetLeague clears all matches, resets weeks and team stats
+// ResetLeague clears all results, resets weeks and team stats and schedules a new season
 func (lm *LeagueManager) ResetLeague() {
-	// Clear matches from database
-	_ = db.ClearAllMatches()
-
 	// Reset team stats in database
 	_ = db.ResetAllTeamStats()
 
-	// Reset local data
+	// Reset local data and replace the fixtures with a fresh set
 	lm.Matches = []models.Match{}
+	if _, err := lm.createFixtures(); err != nil {
+		log.Printf("Failed to create fixtures: %v", err)
+	}
+	if matches, err := db.GetAllMatches(); err == nil {
+		lm.Matches = matches
+	}
 	lm.Week = 0
+	lm.clearPlayoffs()
 	for i := range lm.Teams {
 		lm.Teams[i].Played = 0
 		lm.Teams[i].Wins = 0
@@ -372,52 +541,46 @@ func (lm *LeagueManager) ResetLeague() {
 	lm.updateStandings()
 }
 
-// GetMatchById returns a match by its ID (index in matches slice)
+// GetMatchById returns a match by its database ID
 func (lm *LeagueManager) GetMatchById(matchId int) (models.Match, error) {
-	// Reload matches from database to ensure consistency
-	matches, err := db.GetAllMatches()
-	if err == nil {
-		lm.Matches = matches
+	match, err := db.GetMatchByID(matchId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Match{}, fmt.Errorf("%w: match %d", ErrMatchNotFound, matchId)
 	}
-
-	// Check if matchId is valid (0-based index)
-	if matchId < 0 || matchId >= len(lm.Matches) {
-		return models.Match{}, fmt.Errorf("match with ID %d not found", matchId)
+	if err != nil {
+		return models.Match{}, err
 	}
-
-	return lm.Matches[matchId], nil
+	return *match, nil
 }
 
-// EditMatchResultById edits a match by its ID and recalculates stats
-func (lm *LeagueManager) EditMatchResultById(matchId int, homeGoals, awayGoals int) bool {
+// EditMatchResultById corrects the score of a played match by its database ID,
+// records the change in the audit log and recalculates stats. Scorers entered
+// for the old score are removed.
+func (lm *LeagueManager) EditMatchResultById(matchId int, homeGoals, awayGoals int, reason, actor string) (models.Match, error) {
 	// Reload matches from database to ensure consistency
-	matches, err := db.GetAllMatches()
-	if err == nil {
-		lm.Matches = matches
-	}
+	lm.GetMatches()
 
-	// Check if matchId is valid
-	if matchId < 0 || matchId >= len(lm.Matches) {
-		return false
+	match := lm.findMatch(matchId)
+	if match == nil {
+		return models.Match{}, fmt.Errorf("%w: match %d", ErrMatchNotFound, matchId)
+	}
+	if !match.Played {
+		return models.Match{}, fmt.Errorf("%w: match %d has not been played yet", ErrInvalidResult, matchId)
 	}
 
 	// Validate goals (should be non-negative)
 	if homeGoals < 0 || awayGoals < 0 {
-		return false
+		return models.Match{}, fmt.Errorf("%w: goals cannot be negative", ErrInvalidResult)
 	}
 
-	// Update the match result
-	lm.Matches[matchId].HomeGoals = homeGoals
-	lm.Matches[matchId].AwayGoals = awayGoals
-
-	// Update in database
-	err = db.UpdateMatch(lm.Matches[matchId])
-	if err != nil {
-		return false
+	// Update in database and the audit log
+	if err := lm.changeResult(match, homeGoals, awayGoals, reason, actor, 0, nil); err != nil {
+		return models.Match{}, err
 	}
+	edited := *match
 
 	// Recalculate all team stats based on updated matches
 	lm.recalculateTeamStats()
 
-	return true
+	return edited, nil
 }
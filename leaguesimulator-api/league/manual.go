@@ -0,0 +1,187 @@
+package league
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"leaguesimulator/db"
+	"leaguesimulator/models"
+)
+
+const (
+	settingLeagueMode = "league_mode"
+
+	LeagueModeSimulated = "simulated"
+	LeagueModeManual    = "manual"
+)
+
+// ErrInvalidResult is returned when a submitted result does not fit the stored schedule
+var ErrInvalidResult = errors.New("invalid match result")
+
+// ResultSubmission is a hand-entered full-time score of a fixture with its
+// scorers. HomeTeam, AwayTeam and Week are optional and, when given, must
+// match the stored fixture.
+type ResultSubmission struct {
+	HomeTeam  string        `json:"home_team"`
+	AwayTeam  string        `json:"away_team"`
+	Week      int           `json:"week"`
+	HomeGoals int           `json:"home_goals"`
+	AwayGoals int           `json:"away_goals"`
+	Scorers   []models.Goal `json:"scorers"`
+	Reason    string        `json:"reason"`
+	Actor     string        `json:"actor"`
+}
+
+// loadLeagueMode restores whether results are simulated or entered by hand
+func (lm *LeagueManager) loadLeagueMode() {
+	lm.Mode = LeagueModeSimulated
+	if value, ok, err := db.GetLeagueSetting(settingLeagueMode); err == nil && ok && value == LeagueModeManual {
+		lm.Mode = LeagueModeManual
+	}
+}
+
+// SetLeagueMode switches between simulated weeks and manual result entry
+func (lm *LeagueManager) SetLeagueMode(mode string) error {
+	if mode != LeagueModeSimulated && mode != LeagueModeManual {
+		return fmt.Errorf("%w: mode must be %q or %q", ErrInvalidResult, LeagueModeSimulated, LeagueModeManual)
+	}
+	if err := db.SaveLeagueSetting(settingLeagueMode, mode); err != nil {
+		return err
+	}
+	lm.Mode = mode
+	return nil
+}
+
+// SubmitResult records a hand-entered result for a stored fixture, replaces
+// its scorers and recalculates the standings. A result can be resubmitted to
+// correct it; corrections are kept in the audit log.
+func (lm *LeagueManager) SubmitResult(matchID int, submission ResultSubmission) (*models.Match, []models.Goal, error) {
+	if lm.Mode != LeagueModeManual {
+		return nil, nil, fmt.Errorf("%w: results can only be entered in manual mode", ErrInvalidResult)
+	}
+
+	match := lm.findMatch(matchID)
+	if match == nil {
+		return nil, nil, fmt.Errorf("%w: match %d", ErrMatchNotFound, matchID)
+	}
+	if err := validateSubmission(*match, submission); err != nil {
+		return nil, nil, err
+	}
+
+	scorers := make([]models.Goal, len(submission.Scorers))
+	for i, goal := range submission.Scorers {
+		goal.MatchID = match.ID
+		scorers[i] = goal
+	}
+
+	switch {
+	case !match.Played:
+		updated := *match
+		updated.HomeGoals = submission.HomeGoals
+		updated.AwayGoals = submission.AwayGoals
+		updated.Played = true
+		if err := db.RecordMatchResult(updated, scorers); err != nil {
+			return nil, nil, err
+		}
+		*match = updated
+	case match.HomeGoals == submission.HomeGoals && match.AwayGoals == submission.AwayGoals:
+		if err := db.SaveMatchGoals(match.ID, scorers); err != nil {
+			return nil, nil, err
+		}
+	default:
+		if err := lm.changeResult(match, submission.HomeGoals, submission.AwayGoals, submission.Reason, submission.Actor, 0, scorers); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	result := *match
+	lm.recalculateTeamStats()
+	lm.Week = lm.completedWeeks()
+	lm.checkSeasonEnd()
+
+	goals, err := db.GetMatchGoals(result.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &result, goals, nil
+}
+
+// validateSubmission checks a result against its fixture and makes the
+// scorers of each side add up to the score
+func validateSubmission(match models.Match, submission ResultSubmission) error {
+	if match.Postponed {
+		return fmt.Errorf("%w: match %d is postponed and must be rescheduled first", ErrInvalidResult, match.ID)
+	}
+	if submission.HomeTeam != "" && submission.HomeTeam != match.HomeTeam {
+		return fmt.Errorf("%w: match %d is hosted by %s, not %s", ErrInvalidResult, match.ID, match.HomeTeam, submission.HomeTeam)
+	}
+	if submission.AwayTeam != "" && submission.AwayTeam != match.AwayTeam {
+		return fmt.Errorf("%w: match %d is against %s, not %s", ErrInvalidResult, match.ID, match.AwayTeam, submission.AwayTeam)
+	}
+	if submission.Week != 0 && submission.Week != match.Week {
+		return fmt.Errorf("%w: match %d is scheduled for week %d, not week %d", ErrInvalidResult, match.ID, match.Week, submission.Week)
+	}
+	if submission.HomeGoals < 0 || submission.AwayGoals < 0 {
+		return fmt.Errorf("%w: goals cannot be negative", ErrInvalidResult)
+	}
+
+	homeScored, awayScored := 0, 0
+	for i, goal := range submission.Scorers {
+		if goal.Player == "" {
+			return fmt.Errorf("%w: scorer %d has no player name", ErrInvalidResult, i+1)
+		}
+		if goal.Minute < 0 || goal.Minute > 130 {
+			return fmt.Errorf("%w: scorer %d has an invalid minute %d", ErrInvalidResult, i+1, goal.Minute)
+		}
+
+		forHome := goal.Team == match.HomeTeam
+		switch goal.Team {
+		case match.HomeTeam, match.AwayTeam:
+		default:
+			return fmt.Errorf("%w: scorer %s plays for %q, which is not in this match", ErrInvalidResult, goal.Player, goal.Team)
+		}
+		if goal.OwnGoal {
+			forHome = !forHome
+		}
+		if forHome {
+			homeScored++
+		} else {
+			awayScored++
+		}
+	}
+
+	if homeScored != submission.HomeGoals || awayScored != submission.AwayGoals {
+		return fmt.Errorf("%w: scorers add up to %d-%d but the result is %d-%d",
+			ErrInvalidResult, homeScored, awayScored, submission.HomeGoals, submission.AwayGoals)
+	}
+	return nil
+}
+
+// completedWeeks returns the last week up to which every fixture that is not
+// postponed has been played
+func (lm *LeagueManager) completedWeeks() int {
+	firstOpen := lm.TotalWeeks() + 1
+	for _, match := range lm.Matches {
+		if !match.Played && !match.Postponed && match.Week < firstOpen {
+			firstOpen = match.Week
+		}
+	}
+	return firstOpen - 1
+}
+
+// checkSeasonEnd archives the final table and starts the playoffs once the
+// regular season is complete
+func (lm *LeagueManager) checkSeasonEnd() {
+	if !lm.SeasonComplete() {
+		return
+	}
+	if err := lm.archiveCurrentSeason(); err != nil {
+		log.Printf("Failed to archive season %d: %v", db.CurrentSeason, err)
+	}
+	if lm.Playoffs.Enabled && lm.PlayoffCupID == 0 {
+		if err := lm.startPlayoffs(); err != nil {
+			log.Printf("Failed to start playoffs: %v", err)
+		}
+	}
+}
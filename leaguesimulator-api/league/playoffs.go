@@ -0,0 +1,193 @@
+package league
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"leaguesimulator/db"
+	"leaguesimulator/models"
+)
+
+const (
+	settingPlayoffConfig = "playoff_config"
+	settingPlayoffCup    = "playoff_cup_id"
+
+	PlayoffStatusDisabled      = "disabled"
+	PlayoffStatusRegularSeason = "awaiting_regular_season"
+	PlayoffStatusInProgress    = "in_progress"
+	PlayoffStatusCompleted     = "completed"
+)
+
+// ErrInvalidPlayoff is returned when a playoff request does not fit the league state
+var ErrInvalidPlayoff = errors.New("invalid playoff operation")
+
+// PlayoffConfig decides whether the top teams of the final table play a
+// knockout for the championship after the regular season
+type PlayoffConfig struct {
+	Enabled bool `json:"enabled"`
+	Teams   int  `json:"teams"`
+	Legs    int  `json:"legs"`
+}
+
+type PlayoffStatus struct {
+	Config      PlayoffConfig `json:"config"`
+	Status      string        `json:"status"`
+	Seeds       []string      `json:"seeds"`
+	Bracket     []CupRound    `json:"bracket"`
+	Champion    string        `json:"champion,omitempty"`
+	TableLeader string        `json:"table_leader,omitempty"`
+}
+
+// loadPlayoffSettings restores the playoff configuration and any running playoff bracket
+func (lm *LeagueManager) loadPlayoffSettings() {
+	lm.Playoffs = PlayoffConfig{}
+	lm.PlayoffCupID = 0
+
+	if value, ok, err := db.GetLeagueSetting(settingPlayoffConfig); err == nil && ok {
+		if err := json.Unmarshal([]byte(value), &lm.Playoffs); err != nil {
+			log.Printf("Ignoring invalid playoff configuration: %v", err)
+		}
+	}
+	if value, ok, err := db.GetLeagueSetting(settingPlayoffCup); err == nil && ok {
+		lm.PlayoffCupID, _ = strconv.Atoi(value)
+	}
+}
+
+// ConfigurePlayoffs stores a new playoff configuration. It cannot change once
+// the playoffs have started; enabling them after the regular season has ended
+// starts them straight away.
+func (lm *LeagueManager) ConfigurePlayoffs(config PlayoffConfig) error {
+	if lm.PlayoffCupID != 0 {
+		return fmt.Errorf("%w: the playoffs have already started", ErrInvalidPlayoff)
+	}
+	if config.Teams == 0 {
+		config.Teams = 4
+	}
+	if config.Legs == 0 {
+		config.Legs = 1
+	}
+	if config.Teams < 2 || config.Teams > len(lm.Teams) {
+		return fmt.Errorf("%w: playoff teams must be between 2 and %d", ErrInvalidPlayoff, len(lm.Teams))
+	}
+	if config.Legs != 1 && config.Legs != 2 {
+		return fmt.Errorf("%w: playoff legs must be 1 or 2", ErrInvalidPlayoff)
+	}
+
+	value, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err := db.SaveLeagueSetting(settingPlayoffConfig, string(value)); err != nil {
+		return err
+	}
+
+	lm.Playoffs = config
+	if config.Enabled && lm.SeasonComplete() {
+		return lm.startPlayoffs()
+	}
+	return nil
+}
+
+// startPlayoffs seeds the top teams of the final table into a bracket
+// (1 v 4, 2 v 3 for four teams) and draws its first round
+func (lm *LeagueManager) startPlayoffs() error {
+	standings := lm.GetStandings()
+	if lm.Playoffs.Teams > len(standings) {
+		return fmt.Errorf("%w: not enough teams for a %d-team playoff", ErrInvalidPlayoff, lm.Playoffs.Teams)
+	}
+
+	var seeds []string
+	for _, standing := range standings[:lm.Playoffs.Teams] {
+		seeds = append(seeds, standing.Name)
+	}
+
+	cup, err := saveNewCup("League Playoffs", seeds, lm.Playoffs.Legs, DrawSeeded)
+	if err != nil {
+		return err
+	}
+	if _, err := DrawCupRound(cup); err != nil {
+		return err
+	}
+
+	if err := db.SaveLeagueSetting(settingPlayoffCup, strconv.Itoa(cup.ID)); err != nil {
+		return err
+	}
+	lm.PlayoffCupID = cup.ID
+	return nil
+}
+
+// PlayPlayoffRound plays the current playoff round and draws the next one,
+// storing both in one transaction
+func (lm *LeagueManager) PlayPlayoffRound() ([]models.CupTie, *models.Cup, error) {
+	if lm.PlayoffCupID == 0 {
+		return nil, nil, fmt.Errorf("%w: the playoffs have not started", ErrInvalidPlayoff)
+	}
+
+	stored, err := db.GetCup(lm.PlayoffCupID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if stored.Status == CupStatusCompleted {
+		return nil, nil, fmt.Errorf("%w: the playoffs are already completed", ErrInvalidPlayoff)
+	}
+
+	cup := copyCup(stored)
+	playedRound := cup.CurrentRound
+	if err := playCupRound(&cup); err != nil {
+		return nil, nil, err
+	}
+	if cup.Status != CupStatusCompleted {
+		if err := drawCupRound(&cup); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := db.SaveCupRound(&cup, playedRound); err != nil {
+		return nil, nil, err
+	}
+
+	return cupRoundTies(&cup, playedRound), &cup, nil
+}
+
+// GetPlayoffStatus reports the playoff configuration, bracket and champion next to the table leader
+func (lm *LeagueManager) GetPlayoffStatus() (*PlayoffStatus, error) {
+	status := &PlayoffStatus{
+		Config:  lm.Playoffs,
+		Status:  PlayoffStatusDisabled,
+		Seeds:   []string{},
+		Bracket: []CupRound{},
+	}
+
+	if standings := lm.GetStandings(); len(standings) > 0 && lm.SeasonComplete() {
+		status.TableLeader = standings[0].Name
+	}
+
+	if lm.PlayoffCupID == 0 {
+		if lm.Playoffs.Enabled {
+			status.Status = PlayoffStatusRegularSeason
+		}
+		return status, nil
+	}
+
+	cup, err := db.GetCup(lm.PlayoffCupID)
+	if err != nil {
+		return nil, err
+	}
+
+	status.Status = PlayoffStatusInProgress
+	if cup.Status == CupStatusCompleted {
+		status.Status = PlayoffStatusCompleted
+	}
+	status.Seeds = cup.Entrants
+	status.Bracket = CupBracket(cup)
+	status.Champion = cup.Champion
+	return status, nil
+}
+
+// clearPlayoffs forgets the current playoff bracket, keeping the configuration
+func (lm *LeagueManager) clearPlayoffs() {
+	lm.PlayoffCupID = 0
+	_ = db.DeleteLeagueSetting(settingPlayoffCup)
+}
@@ -0,0 +1,114 @@
+package league
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"leaguesimulator/db"
+	"leaguesimulator/models"
+)
+
+// ErrInvalidPostponement is returned when a fixture cannot be postponed or rescheduled
+var ErrInvalidPostponement = errors.New("invalid postponement")
+
+// PostponeMatch takes an unplayed fixture out of its week. It is left out of
+// PlayNextWeek until it is rescheduled.
+func (lm *LeagueManager) PostponeMatch(matchID int, reason string) (*models.Match, error) {
+	match := lm.findMatch(matchID)
+	if match == nil {
+		return nil, fmt.Errorf("%w: match %d", ErrMatchNotFound, matchID)
+	}
+	if match.Played {
+		return nil, fmt.Errorf("%w: match %d has already been played", ErrInvalidPostponement, matchID)
+	}
+	if match.Postponed {
+		return nil, fmt.Errorf("%w: match %d is already postponed", ErrInvalidPostponement, matchID)
+	}
+	if reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required", ErrInvalidPostponement)
+	}
+
+	match.Postponed = true
+	match.PostponementReason = reason
+	if err := db.UpdateMatchPostponement(*match); err != nil {
+		return nil, err
+	}
+	return match, nil
+}
+
+// RescheduleMatch moves a postponed fixture to a later week. Without a week
+// the fixture goes to the first unplayed week on or after the kick-off date
+// (or to a new week after the last one); without a kick-off it takes the
+// calendar's kick-off for that week.
+func (lm *LeagueManager) RescheduleMatch(matchID int, week int, kickOff *time.Time) (*models.Match, error) {
+	match := lm.findMatch(matchID)
+	if match == nil {
+		return nil, fmt.Errorf("%w: match %d", ErrMatchNotFound, matchID)
+	}
+	if !match.Postponed {
+		return nil, fmt.Errorf("%w: match %d is not postponed", ErrInvalidPostponement, matchID)
+	}
+
+	totalWeeks := lm.TotalWeeks()
+	if week == 0 {
+		if kickOff == nil {
+			return nil, fmt.Errorf("%w: a week or a kick_off date is required", ErrInvalidPostponement)
+		}
+		week = lm.weekOnOrAfter(*kickOff)
+	}
+	if week <= lm.Week {
+		return nil, fmt.Errorf("%w: week %d has already been played", ErrInvalidPostponement, week)
+	}
+	if week > totalWeeks+1 {
+		return nil, fmt.Errorf("%w: week %d leaves a gap after the last week (%d)", ErrInvalidPostponement, week, totalWeeks)
+	}
+
+	if kickOff == nil {
+		kickOff = lm.Calendar.kickOff(week)
+	}
+
+	match.Week = week
+	match.KickOff = kickOff
+	match.Postponed = false
+	if err := db.UpdateMatchPostponement(*match); err != nil {
+		return nil, err
+	}
+
+	rescheduled := *match
+	lm.GetMatches()
+	return &rescheduled, nil
+}
+
+// weekOnOrAfter returns the first unplayed week whose matchday falls on or
+// after the given time, or a new week after the last one
+func (lm *LeagueManager) weekOnOrAfter(kickOff time.Time) int {
+	totalWeeks := lm.TotalWeeks()
+	dates, _ := lm.Calendar.matchdays(totalWeeks)
+
+	day := time.Date(kickOff.Year(), kickOff.Month(), kickOff.Day(), 0, 0, 0, 0, time.UTC)
+	for i, date := range dates {
+		week := i + 1
+		matchday := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		if week > lm.Week && !matchday.Before(day) {
+			return week
+		}
+	}
+	return totalWeeks + 1
+}
+
+// PendingPostponements lists postponed fixtures that have not been rescheduled yet
+func (lm *LeagueManager) PendingPostponements() []models.Match {
+	pending := []models.Match{}
+	for _, match := range lm.Matches {
+		if match.Postponed && !match.Played {
+			pending = append(pending, match)
+		}
+	}
+	return pending
+}
+
+// SeasonComplete reports whether every week has been played and no postponed fixture is left
+func (lm *LeagueManager) SeasonComplete() bool {
+	return lm.Week >= lm.TotalWeeks() && len(lm.PendingPostponements()) == 0
+}
@@ -0,0 +1,181 @@
+package league
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"leaguesimulator/db"
+	"leaguesimulator/models"
+	"leaguesimulator/ratings"
+)
+
+const (
+	settingTeamRatings = "team_ratings"
+
+	// strengthPerGoal converts a goal rate into a team strength: a simulated
+	// side scores about strength/30 goals per match
+	strengthPerGoal = 30
+	minStrength     = 1
+	maxStrength     = 100
+)
+
+// ErrInvalidRatings is returned for rating fit options that make no sense
+var ErrInvalidRatings = errors.New("invalid rating fit")
+
+// RatingFitOptions choose the matches and time decay of a rating fit.
+// FromSeason, when set, leaves out the seasons before it.
+// HalfLifeWeeks is how many weeks old a match is when it counts half; zero
+// uses the default and a negative value weighs every match the same.
+type RatingFitOptions struct {
+	HalfLifeWeeks  float64 `json:"half_life_weeks"`
+	Regularization float64 `json:"regularization,omitempty"`
+	FromSeason     int     `json:"from_season,omitempty"`
+	Apply          bool    `json:"apply"`
+}
+
+// StrengthChange is the strength a fit gives a team next to its current one
+type StrengthChange struct {
+	Team     string `json:"team"`
+	Current  int    `json:"current"`
+	Proposed int    `json:"proposed"`
+}
+
+// RatingFit is a fitted model with the strengths it proposes for the league
+// teams. Unrated lists league teams that have no matches in the data.
+type RatingFit struct {
+	Fit       *ratings.Fit     `json:"fit"`
+	Options   RatingFitOptions `json:"options"`
+	Strengths []StrengthChange `json:"strengths"`
+	Unrated   []string         `json:"unrated"`
+	Applied   bool             `json:"applied"`
+}
+
+// FitRatings fits attack, defence and home advantage to every played match
+// of every season, weighting recent matches more. With Apply set the
+// proposed strengths are written to the teams and the fit is stored for
+// match predictions.
+func (lm *LeagueManager) FitRatings(options RatingFitOptions) (*RatingFit, error) {
+	if options.Regularization < 0 {
+		return nil, fmt.Errorf("%w: regularization cannot be negative", ErrInvalidRatings)
+	}
+	matches, err := lm.archivedMatches()
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []models.HistoricalMatch
+	for _, match := range matches {
+		if options.FromSeason == 0 || match.Season >= options.FromSeason {
+			selected = append(selected, match)
+		}
+	}
+	fit, err := ratings.FitPoisson(timeline(selected), ratings.Options{
+		HalfLife:       options.HalfLifeWeeks,
+		Regularization: options.Regularization,
+	})
+	if errors.Is(err, ratings.ErrNoData) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRatings, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	options.HalfLifeWeeks = fit.Options.HalfLife
+	options.Regularization = fit.Options.Regularization
+
+	result := &RatingFit{Fit: fit, Options: options, Strengths: []StrengthChange{}, Unrated: []string{}}
+	for _, team := range lm.Teams {
+		proposed, ok := fittedStrength(fit, team.Name)
+		if !ok {
+			result.Unrated = append(result.Unrated, team.Name)
+			continue
+		}
+		result.Strengths = append(result.Strengths, StrengthChange{Team: team.Name, Current: team.Strength, Proposed: proposed})
+	}
+	if !options.Apply {
+		return result, nil
+	}
+
+	document, err := json.Marshal(fit)
+	if err != nil {
+		return nil, err
+	}
+	teams := append([]models.Team{}, lm.Teams...)
+	for _, change := range result.Strengths {
+		for i := range teams {
+			if teams[i].Name == change.Team {
+				teams[i].Strength = change.Proposed
+			}
+		}
+	}
+	if err := db.SaveTeams(teams); err != nil {
+		return nil, err
+	}
+	if err := db.SaveLeagueSetting(settingTeamRatings, string(document)); err != nil {
+		return nil, err
+	}
+	lm.Teams = teams
+	result.Applied = true
+	return result, nil
+}
+
+// GetRatings returns the last applied rating fit, or nil when none was applied
+func (lm *LeagueManager) GetRatings() (*ratings.Fit, error) {
+	document, ok, err := db.GetLeagueSetting(settingTeamRatings)
+	if err != nil || !ok || document == "" {
+		return nil, err
+	}
+	var fit ratings.Fit
+	if err := json.Unmarshal([]byte(document), &fit); err != nil {
+		return nil, err
+	}
+	return &fit, nil
+}
+
+// timeline turns matches ordered by season and week into rating matches
+// aged in weeks, with the seasons laid end to end
+func timeline(matches []models.HistoricalMatch) []ratings.Match {
+	seasonWeeks := make(map[int]int)
+	var seasons []int
+	for _, match := range matches {
+		if _, ok := seasonWeeks[match.Season]; !ok {
+			seasons = append(seasons, match.Season)
+		}
+		if match.Week > seasonWeeks[match.Season] {
+			seasonWeeks[match.Season] = match.Week
+		}
+	}
+	offsets := make(map[int]int)
+	total := 0
+	for _, season := range seasons {
+		offsets[season] = total
+		total += seasonWeeks[season]
+	}
+
+	rated := make([]ratings.Match, len(matches))
+	for i, match := range matches {
+		rated[i] = ratings.Match{
+			Home:      match.HomeTeam,
+			Away:      match.AwayTeam,
+			HomeGoals: match.HomeGoals,
+			AwayGoals: match.AwayGoals,
+			Age:       float64(total - offsets[match.Season] - match.Week),
+		}
+	}
+	return rated
+}
+
+// fittedStrength turns a team's fitted ratings into a strength: its scoring
+// rate against an average side, raised by a good defence and lowered by a
+// poor one, so one number keeps the order of the fitted teams
+func fittedStrength(fit *ratings.Fit, team string) (int, bool) {
+	for _, rating := range fit.Teams {
+		if rating.Team != team {
+			continue
+		}
+		strength := strengthPerGoal * math.Exp(fit.Intercept+rating.Attack+rating.Defence)
+		return int(math.Max(minStrength, math.Min(maxStrength, math.Round(strength)))), true
+	}
+	return 0, false
+}
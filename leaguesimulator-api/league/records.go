@@ -0,0 +1,224 @@
+package league
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"leaguesimulator/db"
+	"leaguesimulator/models"
+)
+
+// ErrSeasonNotFound is returned when no matches exist for the requested season
+var ErrSeasonNotFound = errors.New("season not found")
+
+// StreakRecord is a run of consecutive results of one kind by a team
+type StreakRecord struct {
+	Team        string `json:"team"`
+	Length      int    `json:"length"`
+	StartSeason int    `json:"start_season"`
+	StartWeek   int    `json:"start_week"`
+	EndSeason   int    `json:"end_season"`
+	EndWeek     int    `json:"end_week"`
+	Ongoing     bool   `json:"ongoing"`
+}
+
+// TeamCount is a per-team tally such as clean sheets kept
+type TeamCount struct {
+	Team  string `json:"team"`
+	Count int    `json:"count"`
+}
+
+// Records are the streaks, match records and per-team tallies of one season,
+// or of every season when Season is zero
+type Records struct {
+	Season                int                     `json:"season,omitempty"`
+	MatchesPlayed         int                     `json:"matches_played"`
+	TotalGoals            int                     `json:"total_goals"`
+	LongestWinStreak      *StreakRecord           `json:"longest_win_streak"`
+	LongestUnbeatenStreak *StreakRecord           `json:"longest_unbeaten_streak"`
+	LongestLosingStreak   *StreakRecord           `json:"longest_losing_streak"`
+	BiggestWin            *models.HistoricalMatch `json:"biggest_win"`
+	HighestScoringMatch   *models.HistoricalMatch `json:"highest_scoring_match"`
+	CleanSheets           []TeamCount             `json:"clean_sheets"`
+	FailedToScore         []TeamCount             `json:"failed_to_score"`
+}
+
+// SeasonRecords are the all-time records together with those of each season
+type SeasonRecords struct {
+	AllTime Records   `json:"all_time"`
+	Seasons []Records `json:"seasons"`
+}
+
+// archivedMatches returns every played match of every season, including
+// the running one, from historical_matches in the order they were played
+func (lm *LeagueManager) archivedMatches() ([]models.HistoricalMatch, error) {
+	return db.GetHistoricalMatchList()
+}
+
+// GetRecords returns the all-time records and those of every season
+func (lm *LeagueManager) GetRecords() (*SeasonRecords, error) {
+	matches, err := lm.archivedMatches()
+	if err != nil {
+		return nil, err
+	}
+
+	records := &SeasonRecords{AllTime: computeRecords(matches), Seasons: []Records{}}
+	for _, season := range splitSeasons(matches) {
+		seasonRecords := computeRecords(season)
+		seasonRecords.Season = season[0].Season
+		records.Seasons = append(records.Seasons, seasonRecords)
+	}
+	return records, nil
+}
+
+// GetSeasonRecords returns the records of one season
+func (lm *LeagueManager) GetSeasonRecords(season int) (*Records, error) {
+	matches, err := lm.archivedMatches()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, seasonMatches := range splitSeasons(matches) {
+		if seasonMatches[0].Season == season {
+			records := computeRecords(seasonMatches)
+			records.Season = season
+			return &records, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no matches recorded for season %d", ErrSeasonNotFound, season)
+}
+
+// splitSeasons groups matches that are already ordered by season
+func splitSeasons(matches []models.HistoricalMatch) [][]models.HistoricalMatch {
+	var seasons [][]models.HistoricalMatch
+	for i, match := range matches {
+		if i == 0 || match.Season != matches[i-1].Season {
+			seasons = append(seasons, nil)
+		}
+		seasons[len(seasons)-1] = append(seasons[len(seasons)-1], match)
+	}
+	return seasons
+}
+
+// streak follows one kind of run for one team
+type streak struct {
+	current StreakRecord
+	best    StreakRecord
+}
+
+// extend adds a match to the team's run, or ends the run when it does not continue it
+func (s *streak) extend(team string, match models.HistoricalMatch, continues bool) {
+	if !continues {
+		s.current = StreakRecord{}
+		return
+	}
+	if s.current.Length == 0 {
+		s.current = StreakRecord{Team: team, StartSeason: match.Season, StartWeek: match.Week}
+	}
+	s.current.Length++
+	s.current.EndSeason = match.Season
+	s.current.EndWeek = match.Week
+	if s.current.Length > s.best.Length {
+		s.best = s.current
+	}
+}
+
+// computeRecords works out the records of matches ordered by season and week.
+// Streaks carry over from one season to the next.
+func computeRecords(matches []models.HistoricalMatch) Records {
+	records := Records{CleanSheets: []TeamCount{}, FailedToScore: []TeamCount{}}
+	wins := make(map[string]*streak)
+	unbeaten := make(map[string]*streak)
+	losses := make(map[string]*streak)
+	cleanSheets := make(map[string]int)
+	failedToScore := make(map[string]int)
+	var teams []string
+
+	for i := range matches {
+		match := matches[i]
+		records.MatchesPlayed++
+		goals := match.HomeGoals + match.AwayGoals
+		records.TotalGoals += goals
+
+		if margin := absMargin(match); margin > 0 {
+			if best := records.BiggestWin; best == nil || margin > absMargin(*best) ||
+				(margin == absMargin(*best) && goals > best.HomeGoals+best.AwayGoals) {
+				records.BiggestWin = &matches[i]
+			}
+		}
+		if best := records.HighestScoringMatch; best == nil || goals > best.HomeGoals+best.AwayGoals {
+			records.HighestScoringMatch = &matches[i]
+		}
+
+		for _, side := range []struct {
+			team             string
+			scored, conceded int
+		}{
+			{match.HomeTeam, match.HomeGoals, match.AwayGoals},
+			{match.AwayTeam, match.AwayGoals, match.HomeGoals},
+		} {
+			if wins[side.team] == nil {
+				wins[side.team], unbeaten[side.team], losses[side.team] = &streak{}, &streak{}, &streak{}
+				teams = append(teams, side.team)
+			}
+			wins[side.team].extend(side.team, match, side.scored > side.conceded)
+			unbeaten[side.team].extend(side.team, match, side.scored >= side.conceded)
+			losses[side.team].extend(side.team, match, side.scored < side.conceded)
+
+			if side.conceded == 0 {
+				cleanSheets[side.team]++
+			}
+			if side.scored == 0 {
+				failedToScore[side.team]++
+			}
+		}
+	}
+
+	records.LongestWinStreak = longestStreak(teams, wins)
+	records.LongestUnbeatenStreak = longestStreak(teams, unbeaten)
+	records.LongestLosingStreak = longestStreak(teams, losses)
+	for _, team := range teams {
+		records.CleanSheets = append(records.CleanSheets, TeamCount{Team: team, Count: cleanSheets[team]})
+		records.FailedToScore = append(records.FailedToScore, TeamCount{Team: team, Count: failedToScore[team]})
+	}
+	sortTeamCounts(records.CleanSheets)
+	sortTeamCounts(records.FailedToScore)
+	return records
+}
+
+func absMargin(match models.HistoricalMatch) int {
+	if match.HomeGoals > match.AwayGoals {
+		return match.HomeGoals - match.AwayGoals
+	}
+	return match.AwayGoals - match.HomeGoals
+}
+
+// longestStreak returns the longest run of any team, the earliest one on ties
+func longestStreak(teams []string, streaks map[string]*streak) *StreakRecord {
+	var longest *StreakRecord
+	for _, team := range teams {
+		s := streaks[team]
+		if s.best.Length == 0 {
+			continue
+		}
+		best := s.best
+		best.Ongoing = s.current == s.best
+		if longest == nil || best.Length > longest.Length ||
+			(best.Length == longest.Length && (best.EndSeason < longest.EndSeason ||
+				best.EndSeason == longest.EndSeason && best.EndWeek < longest.EndWeek)) {
+			longest = &best
+		}
+	}
+	return longest
+}
+
+// sortTeamCounts orders tallies from highest to lowest, then by name
+func sortTeamCounts(counts []TeamCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Team < counts[j].Team
+	})
+}
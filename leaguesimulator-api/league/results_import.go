@@ -0,0 +1,297 @@
+package league
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"leaguesimulator/db"
+	"leaguesimulator/importer"
+	"leaguesimulator/models"
+)
+
+var (
+	// ErrInvalidAlias is returned for an alias that is empty or points at an unknown team
+	ErrInvalidAlias = errors.New("invalid team alias")
+	// ErrAliasNotFound is returned when deleting an alias that does not exist
+	ErrAliasNotFound = errors.New("team alias not found")
+)
+
+// HistoryImportOptions controls how an imported results file is stored.
+// Imported seasons come before the league's own seasons, which start at 1, so
+// they get negative numbers: by default the newest imported season is the one
+// just before the earliest stored season (-1 on a new league). FirstSeason,
+// when set, numbers them from that negative season instead. Aliases are used
+// for this import only, on top of the stored aliases.
+type HistoryImportOptions struct {
+	FirstSeason int               `json:"first_season,omitempty"`
+	Aliases     map[string]string `json:"aliases,omitempty"`
+	DryRun      bool              `json:"dry_run"`
+}
+
+// UnmappedTeam is a team name from a results file that is not a league team
+// and has no alias
+type UnmappedTeam struct {
+	Name    string `json:"name"`
+	Matches int    `json:"matches"`
+}
+
+// HistoryImportSummary describes what a results import stored, or would store on a dry run
+type HistoryImportSummary struct {
+	DryRun        bool                    `json:"dry_run"`
+	Imported      int                     `json:"imported"`
+	Skipped       int                     `json:"skipped"`
+	Seasons       []models.ImportedSeason `json:"seasons"`
+	UnmappedTeams []UnmappedTeam          `json:"unmapped_teams"`
+}
+
+// GetTeamAliases returns the stored team aliases
+func (lm *LeagueManager) GetTeamAliases() ([]models.TeamAlias, error) {
+	return db.GetTeamAliases()
+}
+
+// SetTeamAlias maps a name used by results files to a league team
+func (lm *LeagueManager) SetTeamAlias(alias, team string) (models.TeamAlias, error) {
+	mapping := models.TeamAlias{Alias: strings.TrimSpace(alias), Team: team}
+	if mapping.Alias == "" || len(mapping.Alias) > 100 {
+		return mapping, fmt.Errorf("%w: the alias must be 1-100 characters", ErrInvalidAlias)
+	}
+	if lm.findTeam(team) == nil {
+		return mapping, fmt.Errorf("%w: team %s does not exist", ErrInvalidAlias, team)
+	}
+	if lm.findTeam(mapping.Alias) != nil {
+		return mapping, fmt.Errorf("%w: %s is already the name of a team", ErrInvalidAlias, mapping.Alias)
+	}
+	return mapping, db.SaveTeamAlias(mapping)
+}
+
+// DeleteTeamAlias removes a stored alias
+func (lm *LeagueManager) DeleteTeamAlias(alias string) error {
+	found, err := db.DeleteTeamAlias(alias)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrAliasNotFound, alias)
+	}
+	return nil
+}
+
+// ImportHistoryFiles parses football-data style results files and imports
+// them as historical seasons
+func (lm *LeagueManager) ImportHistoryFiles(files []io.Reader, options HistoryImportOptions) (*HistoryImportSummary, error) {
+	var rows []importer.ResultRow
+	for _, file := range files {
+		parsed, err := importer.ParseResults(file)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, parsed...)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Date.Before(rows[j].Date) })
+	return lm.ImportHistory(rows, options)
+}
+
+// ImportHistory stores played matches from a results file in
+// historical_matches so that records, head-to-heads and the predictor can use
+// them. Team names are matched to league teams directly (ignoring case) or
+// through an alias; matches involving any other team are skipped and the
+// names are reported. Rows must be ordered by date. An imported season may
+// not reuse the number of a season that is already stored, and a season whose
+// years were imported before is refused.
+func (lm *LeagueManager) ImportHistory(rows []importer.ResultRow, options HistoryImportOptions) (*HistoryImportSummary, error) {
+	resolve, err := lm.teamResolver(options.Aliases)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &HistoryImportSummary{DryRun: options.DryRun, Seasons: []models.ImportedSeason{}, UnmappedTeams: []UnmappedTeam{}}
+	unmapped := make(map[string]int)
+	var unmappedOrder []string
+	var years []int
+	bySeason := make(map[int][]models.HistoricalMatch)
+	dates := make(map[int][]time.Time)
+
+	for _, row := range rows {
+		home, homeOK := resolve(row.HomeTeam)
+		away, awayOK := resolve(row.AwayTeam)
+		for _, side := range []struct {
+			name string
+			ok   bool
+		}{{row.HomeTeam, homeOK}, {row.AwayTeam, awayOK}} {
+			if side.ok {
+				continue
+			}
+			if unmapped[side.name] == 0 {
+				unmappedOrder = append(unmappedOrder, side.name)
+			}
+			unmapped[side.name]++
+		}
+		if !homeOK || !awayOK || home == away {
+			summary.Skipped++
+			continue
+		}
+
+		if _, ok := bySeason[row.SeasonYear]; !ok {
+			years = append(years, row.SeasonYear)
+		}
+		bySeason[row.SeasonYear] = append(bySeason[row.SeasonYear], models.HistoricalMatch{
+			HomeTeam:  home,
+			AwayTeam:  away,
+			HomeGoals: row.HomeGoals,
+			AwayGoals: row.AwayGoals,
+		})
+		dates[row.SeasonYear] = append(dates[row.SeasonYear], row.Date)
+	}
+	for _, name := range unmappedOrder {
+		summary.UnmappedTeams = append(summary.UnmappedTeams, UnmappedTeam{Name: name, Matches: unmapped[name]})
+	}
+	if len(years) == 0 {
+		return nil, fmt.Errorf("%w: no match is between two league teams; add aliases for the unmapped teams", importer.ErrInvalidImport)
+	}
+	sort.Ints(years)
+
+	taken, err := lm.storedSeasons()
+	if err != nil {
+		return nil, err
+	}
+	imported, err := db.GetImportedSeasons()
+	if err != nil {
+		return nil, err
+	}
+	importedAs := make(map[string]int)
+	for _, season := range imported {
+		// Seasons whose matches were all removed with their teams can be imported again
+		if taken[season.Season] {
+			importedAs[season.Label] = season.Season
+		}
+	}
+	first := options.FirstSeason
+	if first == 0 {
+		earliest := 0
+		for season := range taken {
+			if season < earliest {
+				earliest = season
+			}
+		}
+		first = earliest - len(years)
+	}
+	if last := first + len(years) - 1; last >= 0 {
+		return nil, fmt.Errorf("%w: imported seasons must come before the league's own seasons; season %d is not negative, choose a lower first_season", importer.ErrInvalidImport, last)
+	}
+
+	var matches []models.HistoricalMatch
+	for i, year := range years {
+		season := first + i
+		label := seasonLabel(year)
+		if existing, ok := importedAs[label]; ok {
+			return nil, fmt.Errorf("%w: %s was already imported as season %d", importer.ErrInvalidImport, label, existing)
+		}
+		if taken[season] {
+			return nil, fmt.Errorf("%w: season %d is already stored; choose another first_season", importer.ErrInvalidImport, season)
+		}
+
+		seasonMatches := bySeason[year]
+		weeks := numberWeeks(seasonMatches)
+		for j := range seasonMatches {
+			seasonMatches[j].Season = season
+		}
+		seasonDates := dates[year]
+		summary.Seasons = append(summary.Seasons, models.ImportedSeason{
+			Season:    season,
+			Label:     label,
+			Matches:   len(seasonMatches),
+			Weeks:     weeks,
+			FirstDate: seasonDates[0],
+			LastDate:  seasonDates[len(seasonDates)-1],
+		})
+		matches = append(matches, seasonMatches...)
+	}
+	summary.Imported = len(matches)
+
+	if options.DryRun {
+		return summary, nil
+	}
+	if err := db.ImportHistoricalSeasons(summary.Seasons, matches); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// seasonLabel names a season that starts in the given year, such as "2019/20"
+func seasonLabel(year int) string {
+	return fmt.Sprintf("%d/%02d", year, (year+1)%100)
+}
+
+// teamResolver returns a lookup from a results file name to a league team,
+// trying the team names, then the extra aliases, then the stored aliases,
+// all without regard to case
+func (lm *LeagueManager) teamResolver(extra map[string]string) (func(name string) (string, bool), error) {
+	stored, err := db.GetTeamAliases()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string)
+	for _, alias := range stored {
+		names[strings.ToLower(alias.Alias)] = alias.Team
+	}
+	for alias, team := range extra {
+		if lm.findTeam(team) == nil {
+			return nil, fmt.Errorf("%w: alias %s points at unknown team %s", ErrInvalidAlias, alias, team)
+		}
+		names[strings.ToLower(strings.TrimSpace(alias))] = team
+	}
+	for _, team := range lm.Teams {
+		names[strings.ToLower(team.Name)] = team.Name
+	}
+
+	return func(name string) (string, bool) {
+		team, ok := names[strings.ToLower(strings.TrimSpace(name))]
+		return team, ok
+	}, nil
+}
+
+// storedSeasons returns the numbers of every season in historical_matches or the archive
+func (lm *LeagueManager) storedSeasons() (map[int]bool, error) {
+	taken := make(map[int]bool)
+	matches, err := db.GetHistoricalMatchList()
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range matches {
+		taken[match.Season] = true
+	}
+	standings, err := db.GetAllSeasonStandings()
+	if err != nil {
+		return nil, err
+	}
+	for _, standing := range standings {
+		taken[standing.Season] = true
+	}
+	return taken, nil
+}
+
+// numberWeeks gives matches ordered by date a week number: the week after
+// the later of the two teams' previous matches. It returns the number of weeks.
+func numberWeeks(matches []models.HistoricalMatch) int {
+	played := make(map[string]int)
+	weeks := 0
+	for i := range matches {
+		home, away := matches[i].HomeTeam, matches[i].AwayTeam
+		week := played[home]
+		if played[away] > week {
+			week = played[away]
+		}
+		week++
+		matches[i].Week = week
+		played[home], played[away] = week, week
+		if week > weeks {
+			weeks = week
+		}
+	}
+	return weeks
+}
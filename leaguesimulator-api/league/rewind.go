@@ -0,0 +1,52 @@
+package league
+
+import (
+	"errors"
+	"fmt"
+
+	"leaguesimulator/db"
+)
+
+// ErrInvalidRewind is returned when the league cannot be rewound to the requested week
+var ErrInvalidRewind = errors.New("invalid rewind")
+
+// RewindToWeek undoes every result after the given week so the rest of the
+// season can be replayed. Fixtures stay in place as unplayed matches; their
+// historical rows and later predictions are removed, any playoff bracket is
+// dropped and the standings are rebuilt. Team strengths do not change with
+// results, so there are no rating changes to undo.
+func (lm *LeagueManager) RewindToWeek(week int) (db.RewindSummary, error) {
+	if week < 0 {
+		return db.RewindSummary{}, fmt.Errorf("%w: week cannot be negative", ErrInvalidRewind)
+	}
+
+	lm.GetMatches()
+	lastPlayed := 0
+	for _, match := range lm.Matches {
+		if match.Played && match.Week > lastPlayed {
+			lastPlayed = match.Week
+		}
+	}
+	if week >= lastPlayed {
+		return db.RewindSummary{}, fmt.Errorf("%w: nothing has been played after week %d", ErrInvalidRewind, week)
+	}
+
+	summary, err := db.RewindMatches(week)
+	if err != nil {
+		return summary, err
+	}
+
+	lm.GetMatches()
+	lm.clearPlayoffs()
+	lm.recalculateTeamStats()
+	lm.Week = lm.completedWeeks()
+	return summary, nil
+}
+
+// UndoLastWeek rewinds the league to the week before the current one
+func (lm *LeagueManager) UndoLastWeek() (db.RewindSummary, error) {
+	if lm.Week == 0 {
+		return db.RewindSummary{}, fmt.Errorf("%w: no week has been played yet", ErrInvalidRewind)
+	}
+	return lm.RewindToWeek(lm.Week - 1)
+}
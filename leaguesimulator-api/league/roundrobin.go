@@ -0,0 +1,61 @@
+package league
+
+import "leaguesimulator/models"
+
+// roundRobin pairs every team with every other team once using the circle
+// method. Each round lists {home, away} pairs; with an odd number of teams one
+// team rests in every round.
+func roundRobin(teams []string) [][][2]string {
+	order := append([]string{}, teams...)
+	if len(order)%2 == 1 {
+		order = append(order, "")
+	}
+
+	n := len(order)
+	var rounds [][][2]string
+	for round := 0; round < n-1; round++ {
+		var pairs [][2]string
+		for i := 0; i < n/2; i++ {
+			home, away := order[i], order[n-1-i]
+			if home == "" || away == "" {
+				continue
+			}
+			// Alternate the fixed team between home and away
+			if i == 0 && round%2 == 1 {
+				home, away = away, home
+			}
+			pairs = append(pairs, [2]string{home, away})
+		}
+		rounds = append(rounds, pairs)
+
+		// Rotate every team except the first one
+		last := order[n-1]
+		copy(order[2:], order[1:n-1])
+		order[1] = last
+	}
+	return rounds
+}
+
+// doubleRoundRobin builds unplayed fixtures in which every team meets every
+// other team twice, the second half repeating the first with venues swapped
+func doubleRoundRobin(teams []string) []models.Match {
+	rounds := roundRobin(teams)
+
+	var fixtures []models.Match
+	for leg := 0; leg < 2; leg++ {
+		for r, pairs := range rounds {
+			for _, pair := range pairs {
+				home, away := pair[0], pair[1]
+				if leg == 1 {
+					home, away = away, home
+				}
+				fixtures = append(fixtures, models.Match{
+					Week:     leg*len(rounds) + r + 1,
+					HomeTeam: home,
+					AwayTeam: away,
+				})
+			}
+		}
+	}
+	return fixtures
+}
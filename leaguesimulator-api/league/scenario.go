@@ -0,0 +1,199 @@
+package league
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"leaguesimulator/models"
+)
+
+const (
+	defaultSimulations = 1000
+	maxSimulations     = 20000
+)
+
+// ErrInvalidScenario is returned when hypothetical results do not fit the remaining fixtures
+var ErrInvalidScenario = errors.New("invalid scenario")
+
+// HypotheticalResult is an assumed score for an unplayed fixture, chosen by
+// match ID or by its home and away team
+type HypotheticalResult struct {
+	MatchID   int    `json:"match_id"`
+	HomeTeam  string `json:"home_team"`
+	AwayTeam  string `json:"away_team"`
+	HomeGoals int    `json:"home_goals"`
+	AwayGoals int    `json:"away_goals"`
+}
+
+// ScenarioOutcome is the table and title odds of one version of the league
+type ScenarioOutcome struct {
+	Standings                 []TeamStanding     `json:"standings"`
+	ChampionshipProbabilities map[string]float64 `json:"championship_probabilities"`
+}
+
+// ScenarioChange compares one team between the baseline and the scenario
+type ScenarioChange struct {
+	Team             string  `json:"team"`
+	BaselinePosition int     `json:"baseline_position"`
+	ScenarioPosition int     `json:"scenario_position"`
+	BaselineOdds     float64 `json:"baseline_odds"`
+	ScenarioOdds     float64 `json:"scenario_odds"`
+	OddsChange       float64 `json:"odds_change"`
+}
+
+type ScenarioResult struct {
+	Applied     []models.Match   `json:"applied_results"`
+	Baseline    ScenarioOutcome  `json:"baseline"`
+	Scenario    ScenarioOutcome  `json:"scenario"`
+	Changes     []ScenarioChange `json:"changes"`
+	Simulations int              `json:"simulations"`
+}
+
+// copyState returns a LeagueManager holding its own copies of the teams and
+// matches, so results can be changed without touching the real league
+func (lm *LeagueManager) copyState() *LeagueManager {
+	copied := *lm
+	copied.Teams = append([]models.Team{}, lm.Teams...)
+	copied.Matches = append([]models.Match{}, lm.Matches...)
+	copied.Standings = nil
+	return &copied
+}
+
+// RunScenario applies hypothetical results to a copy of the league and
+// compares its standings and championship odds with the current league.
+// Nothing is saved.
+func (lm *LeagueManager) RunScenario(results []HypotheticalResult, simulations int) (*ScenarioResult, error) {
+	if len(results) == 0 {
+		return nil, fmt.Errorf("%w: at least one hypothetical result is required", ErrInvalidScenario)
+	}
+	if simulations == 0 {
+		simulations = defaultSimulations
+	}
+	if simulations < 1 || simulations > maxSimulations {
+		return nil, fmt.Errorf("%w: simulations must be between 1 and %d", ErrInvalidScenario, maxSimulations)
+	}
+
+	lm.GetMatches()
+	scenario := lm.copyState()
+
+	applied := []models.Match{}
+	for _, result := range results {
+		match, err := scenario.findHypotheticalFixture(result)
+		if err != nil {
+			return nil, err
+		}
+		if result.HomeGoals < 0 || result.AwayGoals < 0 {
+			return nil, fmt.Errorf("%w: goals cannot be negative", ErrInvalidScenario)
+		}
+
+		match.HomeGoals = result.HomeGoals
+		match.AwayGoals = result.AwayGoals
+		match.Played = true
+		match.Postponed = false
+		applied = append(applied, *match)
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	baseline := lm.copyState().outcome(simulations, rng)
+	outcome := scenario.outcome(simulations, rng)
+
+	changes := []ScenarioChange{}
+	for position, standing := range outcome.Standings {
+		change := ScenarioChange{
+			Team:             standing.Name,
+			ScenarioPosition: position + 1,
+			ScenarioOdds:     outcome.ChampionshipProbabilities[standing.Name],
+			BaselineOdds:     baseline.ChampionshipProbabilities[standing.Name],
+		}
+		for basePosition, baseStanding := range baseline.Standings {
+			if baseStanding.Name == standing.Name {
+				change.BaselinePosition = basePosition + 1
+			}
+		}
+		change.OddsChange = change.ScenarioOdds - change.BaselineOdds
+		changes = append(changes, change)
+	}
+
+	return &ScenarioResult{
+		Applied:     applied,
+		Baseline:    baseline,
+		Scenario:    outcome,
+		Changes:     changes,
+		Simulations: simulations,
+	}, nil
+}
+
+// findHypotheticalFixture finds the unplayed fixture a hypothetical result refers to
+func (lm *LeagueManager) findHypotheticalFixture(result HypotheticalResult) (*models.Match, error) {
+	if result.MatchID != 0 {
+		match := lm.findMatch(result.MatchID)
+		if match == nil {
+			return nil, fmt.Errorf("%w: match %d does not exist", ErrInvalidScenario, result.MatchID)
+		}
+		if match.Played {
+			return nil, fmt.Errorf("%w: match %d has already been played or is set twice", ErrInvalidScenario, result.MatchID)
+		}
+		return match, nil
+	}
+
+	for i := range lm.Matches {
+		match := &lm.Matches[i]
+		if !match.Played && match.HomeTeam == result.HomeTeam && match.AwayTeam == result.AwayTeam {
+			return match, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no unplayed fixture %s v %s", ErrInvalidScenario, result.HomeTeam, result.AwayTeam)
+}
+
+// outcome returns the current table of the league and its title odds from
+// Monte Carlo runs of the remaining fixtures
+func (lm *LeagueManager) outcome(simulations int, rng *rand.Rand) ScenarioOutcome {
+	lm.updateStandings()
+	return ScenarioOutcome{
+		Standings:                 lm.Standings,
+		ChampionshipProbabilities: lm.simulateChampionship(simulations, rng),
+	}
+}
+
+// simulateChampionship plays the remaining fixtures many times with the match
+// engine and returns how often each team finishes top, in percent
+func (lm *LeagueManager) simulateChampionship(simulations int, rng *rand.Rand) map[string]float64 {
+	teamNames := make([]string, 0, len(lm.Teams))
+	teams := make(map[string]*models.Team)
+	for i := range lm.Teams {
+		teamNames = append(teamNames, lm.Teams[i].Name)
+		teams[lm.Teams[i].Name] = &lm.Teams[i]
+	}
+
+	var remaining []int
+	for i, match := range lm.Matches {
+		if !match.Played {
+			remaining = append(remaining, i)
+		}
+	}
+
+	titles := make(map[string]int)
+	matches := append([]models.Match{}, lm.Matches...)
+	for run := 0; run < simulations; run++ {
+		for _, i := range remaining {
+			home, away := teams[matches[i].HomeTeam], teams[matches[i].AwayTeam]
+			if home == nil || away == nil {
+				continue
+			}
+			matches[i].HomeGoals, matches[i].AwayGoals = drawScore(rng.Intn, home, away)
+			matches[i].Played = true
+		}
+
+		if table := computeStandings(teamNames, matches); len(table) > 0 {
+			titles[table[0].Name]++
+		}
+	}
+
+	probabilities := make(map[string]float64)
+	for _, name := range teamNames {
+		probabilities[name] = float64(titles[name]) / float64(simulations) * 100
+	}
+	return probabilities
+}
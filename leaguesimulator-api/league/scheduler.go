@@ -0,0 +1,524 @@
+package league
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"leaguesimulator/db"
+	"leaguesimulator/models"
+)
+
+const (
+	settingSchedulingConstraints = "scheduling_constraints"
+
+	ConstraintConsecutiveHome = "max_consecutive_home"
+	ConstraintSharedVenue     = "shared_venue"
+	ConstraintBlockedDate     = "blocked_date"
+	ConstraintDerbyWeek       = "derby_week"
+
+	BlockHome = "home"
+	BlockAll  = "all"
+
+	schedulerIterations = 20000
+	schedulerRestarts   = 5
+)
+
+// ErrInvalidSchedule is returned when scheduling constraints or a schedule request cannot be used
+var ErrInvalidSchedule = errors.New("invalid scheduling request")
+
+// BlockedDate is a day on which a team cannot host a match (its stadium is
+// unavailable) or, with kind "all", cannot play at all
+type BlockedDate struct {
+	Team   string `json:"team"`
+	Date   string `json:"date"`
+	Kind   string `json:"kind"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// DerbyRule places the meetings of two teams on chosen weeks, at most one
+// week per half of the season
+type DerbyRule struct {
+	Teams [2]string `json:"teams"`
+	Weeks []int     `json:"weeks"`
+}
+
+// SchedulingConstraints are the rules a generated fixture list has to respect
+type SchedulingConstraints struct {
+	MaxConsecutiveHome   int           `json:"max_consecutive_home"`
+	SeparateSharedVenues bool          `json:"separate_shared_venues"`
+	BlockedDates         []BlockedDate `json:"blocked_dates"`
+	Derbies              []DerbyRule   `json:"derbies"`
+}
+
+// ConstraintViolation describes one rule the schedule does not meet
+type ConstraintViolation struct {
+	Constraint string   `json:"constraint"`
+	Week       int      `json:"week,omitempty"`
+	Teams      []string `json:"teams"`
+	Detail     string   `json:"detail"`
+}
+
+// ScheduleReport is the outcome of building or checking a fixture list
+type ScheduleReport struct {
+	Constraints SchedulingConstraints `json:"constraints"`
+	Satisfied   bool                  `json:"satisfied"`
+	Violations  []ConstraintViolation `json:"violations"`
+	TotalWeeks  int                   `json:"total_weeks"`
+}
+
+// defaultConstraints only limits home runs and keeps ground-sharing teams apart
+func defaultConstraints() SchedulingConstraints {
+	return SchedulingConstraints{
+		MaxConsecutiveHome:   2,
+		SeparateSharedVenues: true,
+		BlockedDates:         []BlockedDate{},
+		Derbies:              []DerbyRule{},
+	}
+}
+
+// loadSchedulingSettings restores the stored constraints, falling back to the defaults
+func (lm *LeagueManager) loadSchedulingSettings() {
+	lm.Constraints = defaultConstraints()
+
+	value, ok, err := db.GetLeagueSetting(settingSchedulingConstraints)
+	if err != nil || !ok {
+		return
+	}
+
+	var constraints SchedulingConstraints
+	if err := json.Unmarshal([]byte(value), &constraints); err != nil {
+		log.Printf("Ignoring invalid scheduling constraints: %v", err)
+		return
+	}
+	if err := lm.validateConstraints(&constraints); err != nil {
+		log.Printf("Ignoring invalid scheduling constraints: %v", err)
+		return
+	}
+	lm.Constraints = constraints
+}
+
+// validateConstraints checks team names, dates and weeks and fills in defaults
+func (lm *LeagueManager) validateConstraints(constraints *SchedulingConstraints) error {
+	if constraints.MaxConsecutiveHome < 0 {
+		return fmt.Errorf("%w: max_consecutive_home cannot be negative", ErrInvalidSchedule)
+	}
+	if constraints.BlockedDates == nil {
+		constraints.BlockedDates = []BlockedDate{}
+	}
+	if constraints.Derbies == nil {
+		constraints.Derbies = []DerbyRule{}
+	}
+
+	for i := range constraints.BlockedDates {
+		blocked := &constraints.BlockedDates[i]
+		if lm.findTeam(blocked.Team) == nil {
+			return fmt.Errorf("%w: unknown team %q in blocked dates", ErrInvalidSchedule, blocked.Team)
+		}
+		if _, err := time.Parse("2006-01-02", blocked.Date); err != nil {
+			return fmt.Errorf("%w: blocked date %q must be YYYY-MM-DD", ErrInvalidSchedule, blocked.Date)
+		}
+		if blocked.Kind == "" {
+			blocked.Kind = BlockHome
+		}
+		if blocked.Kind != BlockHome && blocked.Kind != BlockAll {
+			return fmt.Errorf("%w: blocked date kind must be %q or %q", ErrInvalidSchedule, BlockHome, BlockAll)
+		}
+	}
+
+	for _, derby := range constraints.Derbies {
+		if derby.Teams[0] == derby.Teams[1] {
+			return fmt.Errorf("%w: a derby needs two different teams", ErrInvalidSchedule)
+		}
+		for _, name := range derby.Teams {
+			if lm.findTeam(name) == nil {
+				return fmt.Errorf("%w: unknown team %q in derbies", ErrInvalidSchedule, name)
+			}
+		}
+		if len(derby.Weeks) == 0 || len(derby.Weeks) > 2 {
+			return fmt.Errorf("%w: a derby needs one or two weeks", ErrInvalidSchedule)
+		}
+		for _, week := range derby.Weeks {
+			if week < 1 {
+				return fmt.Errorf("%w: derby weeks start at 1", ErrInvalidSchedule)
+			}
+		}
+	}
+	return nil
+}
+
+// SchedulingConstraints returns a copy of the current constraints that can
+// be changed without touching the league's own
+func (lm *LeagueManager) SchedulingConstraints() SchedulingConstraints {
+	constraints := lm.Constraints
+	constraints.BlockedDates = append([]BlockedDate{}, lm.Constraints.BlockedDates...)
+	constraints.Derbies = make([]DerbyRule, len(lm.Constraints.Derbies))
+	for i, derby := range lm.Constraints.Derbies {
+		derby.Weeks = append([]int{}, derby.Weeks...)
+		constraints.Derbies[i] = derby
+	}
+	return constraints
+}
+
+// ConfigureScheduling stores new scheduling constraints. They are applied the
+// next time fixtures are generated.
+func (lm *LeagueManager) ConfigureScheduling(constraints SchedulingConstraints) error {
+	if err := lm.validateConstraints(&constraints); err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(constraints)
+	if err != nil {
+		return err
+	}
+	if err := db.SaveLeagueSetting(settingSchedulingConstraints, string(value)); err != nil {
+		return err
+	}
+
+	lm.Constraints = constraints
+	return nil
+}
+
+// CheckSchedule reports how well the stored fixtures meet the current constraints
+func (lm *LeagueManager) CheckSchedule() ScheduleReport {
+	fixtures := lm.GetMatches()
+	violations, _ := lm.evaluateSchedule(fixtures, lm.TotalWeeks(), lm.matchdayWeeks(lm.TotalWeeks()))
+	return lm.newScheduleReport(violations, lm.TotalWeeks())
+}
+
+// RegenerateFixtures throws away the unplayed season and builds a new
+// constrained fixture list. It is only allowed before the first match is played.
+func (lm *LeagueManager) RegenerateFixtures() (ScheduleReport, error) {
+	for _, match := range lm.GetMatches() {
+		if match.Played {
+			return ScheduleReport{}, fmt.Errorf("%w: fixtures cannot be regenerated once the season has started", ErrInvalidSchedule)
+		}
+	}
+
+	report, err := lm.createFixtures()
+	if err != nil {
+		return report, err
+	}
+	lm.GetMatches()
+	lm.Week = 0
+	lm.updateStandings()
+	return report, nil
+}
+
+func (lm *LeagueManager) newScheduleReport(violations []ConstraintViolation, totalWeeks int) ScheduleReport {
+	if violations == nil {
+		violations = []ConstraintViolation{}
+	}
+	return ScheduleReport{
+		Constraints: lm.Constraints,
+		Satisfied:   len(violations) == 0,
+		Violations:  violations,
+		TotalWeeks:  totalWeeks,
+	}
+}
+
+// solveSchedule searches for a double round-robin that breaks as few
+// constraints as possible. The pairings of each round come from the circle
+// method; the search reorders the rounds of both halves and swaps home and
+// away within a pairing (the return leg is always the reverse fixture).
+func (lm *LeagueManager) solveSchedule(teams []string) ([]models.Match, []ConstraintViolation) {
+	rounds := roundRobin(teams)
+	totalWeeks := 2 * len(rounds)
+	if len(rounds) == 0 {
+		return []models.Match{}, nil
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	weekOfDate := lm.matchdayWeeks(totalWeeks)
+
+	var best *schedulePlan
+	bestCost := math.MaxInt
+	for restart := 0; restart < schedulerRestarts && bestCost > 0; restart++ {
+		plan := newSchedulePlan(rounds)
+		if restart > 0 {
+			plan.shuffle(rng)
+		}
+		cost := lm.scheduleCost(plan.fixtures(), totalWeeks, weekOfDate)
+
+		temperature := 2.0
+		for i := 0; i < schedulerIterations && cost > 0; i++ {
+			undo := plan.mutate(rng)
+			next := lm.scheduleCost(plan.fixtures(), totalWeeks, weekOfDate)
+			if next <= cost || rng.Float64() < math.Exp(float64(cost-next)/temperature) {
+				cost = next
+			} else {
+				undo()
+			}
+			temperature = math.Max(0.05, temperature*0.9995)
+		}
+
+		if cost < bestCost {
+			bestCost = cost
+			best = plan.clone()
+		}
+	}
+
+	fixtures := best.fixtures()
+	violations, _ := lm.evaluateSchedule(fixtures, totalWeeks, weekOfDate)
+	return fixtures, violations
+}
+
+func (lm *LeagueManager) scheduleCost(fixtures []models.Match, totalWeeks int, weekOfDate map[string]int) int {
+	_, cost := lm.evaluateSchedule(fixtures, totalWeeks, weekOfDate)
+	return cost
+}
+
+// matchdayWeeks maps the calendar date of every week to its week number, so
+// that blocked dates can be placed. The search works it out once rather than
+// on every evaluation.
+func (lm *LeagueManager) matchdayWeeks(totalWeeks int) map[string]int {
+	weekOfDate := make(map[string]int)
+	if len(lm.Constraints.BlockedDates) == 0 {
+		return weekOfDate
+	}
+	dates, _ := lm.Calendar.matchdays(totalWeeks)
+	for i, date := range dates {
+		weekOfDate[date.Format("2006-01-02")] = i + 1
+	}
+	return weekOfDate
+}
+
+// evaluateSchedule lists every broken constraint together with a cost that
+// grows with how badly each one is broken. weekOfDate comes from matchdayWeeks.
+func (lm *LeagueManager) evaluateSchedule(fixtures []models.Match, totalWeeks int, weekOfDate map[string]int) ([]ConstraintViolation, int) {
+	var violations []ConstraintViolation
+	cost := 0
+
+	byWeek := make(map[int][]models.Match)
+	for _, fixture := range fixtures {
+		byWeek[fixture.Week] = append(byWeek[fixture.Week], fixture)
+	}
+
+	// Runs of home games, counted over the matches a team actually plays
+	if limit := lm.Constraints.MaxConsecutiveHome; limit > 0 {
+		type appearance struct {
+			week int
+			home bool
+		}
+		sequences := make(map[string][]appearance)
+		for week := 1; week <= totalWeeks; week++ {
+			for _, fixture := range byWeek[week] {
+				sequences[fixture.HomeTeam] = append(sequences[fixture.HomeTeam], appearance{week, true})
+				sequences[fixture.AwayTeam] = append(sequences[fixture.AwayTeam], appearance{week, false})
+			}
+		}
+
+		for _, team := range lm.Teams {
+			run, runStart := 0, 0
+			flush := func() {
+				if run > limit {
+					violations = append(violations, ConstraintViolation{
+						Constraint: ConstraintConsecutiveHome,
+						Week:       runStart,
+						Teams:      []string{team.Name},
+						Detail:     fmt.Sprintf("%s play %d home games in a row from week %d (limit %d)", team.Name, run, runStart, limit),
+					})
+					cost += run - limit
+				}
+				run = 0
+			}
+			for _, game := range sequences[team.Name] {
+				if !game.home {
+					flush()
+					continue
+				}
+				if run == 0 {
+					runStart = game.week
+				}
+				run++
+			}
+			flush()
+		}
+	}
+
+	// Teams sharing a ground cannot both be at home in the same week
+	if lm.Constraints.SeparateSharedVenues {
+		venues := make(map[string]int)
+		for _, team := range lm.Teams {
+			venues[team.Name] = team.VenueID
+		}
+		for week := 1; week <= totalWeeks; week++ {
+			hosts := make(map[int][]string)
+			for _, fixture := range byWeek[week] {
+				if venueID := venues[fixture.HomeTeam]; venueID != 0 {
+					hosts[venueID] = append(hosts[venueID], fixture.HomeTeam)
+				}
+			}
+			venueIDs := make([]int, 0, len(hosts))
+			for venueID := range hosts {
+				venueIDs = append(venueIDs, venueID)
+			}
+			sort.Ints(venueIDs)
+			for _, venueID := range venueIDs {
+				if teams := hosts[venueID]; len(teams) > 1 {
+					violations = append(violations, ConstraintViolation{
+						Constraint: ConstraintSharedVenue,
+						Week:       week,
+						Teams:      teams,
+						Detail:     fmt.Sprintf("%d teams sharing venue %d are at home in week %d", len(teams), venueID, week),
+					})
+					cost += 3 * (len(teams) - 1)
+				}
+			}
+		}
+	}
+
+	// Blocked dates map onto weeks through the calendar
+	if len(lm.Constraints.BlockedDates) > 0 {
+		for _, blocked := range lm.Constraints.BlockedDates {
+			week, ok := weekOfDate[blocked.Date]
+			if !ok {
+				continue
+			}
+			for _, fixture := range byWeek[week] {
+				home := fixture.HomeTeam == blocked.Team
+				away := fixture.AwayTeam == blocked.Team
+				if home || (away && blocked.Kind == BlockAll) {
+					violations = append(violations, ConstraintViolation{
+						Constraint: ConstraintBlockedDate,
+						Week:       week,
+						Teams:      []string{blocked.Team},
+						Detail:     fmt.Sprintf("%s cannot play %s on %s (week %d)", blocked.Team, blockedKindText(blocked.Kind), blocked.Date, week),
+					})
+					cost += 3
+				}
+			}
+		}
+	}
+
+	// Derbies must fall on their chosen weeks
+	for _, derby := range lm.Constraints.Derbies {
+		for _, week := range derby.Weeks {
+			if week > totalWeeks {
+				violations = append(violations, ConstraintViolation{
+					Constraint: ConstraintDerbyWeek,
+					Week:       week,
+					Teams:      derby.Teams[:],
+					Detail:     fmt.Sprintf("week %d is beyond the %d-week season", week, totalWeeks),
+				})
+				cost += 3
+				continue
+			}
+			found := false
+			for _, fixture := range byWeek[week] {
+				if (fixture.HomeTeam == derby.Teams[0] && fixture.AwayTeam == derby.Teams[1]) ||
+					(fixture.HomeTeam == derby.Teams[1] && fixture.AwayTeam == derby.Teams[0]) {
+					found = true
+				}
+			}
+			if !found {
+				violations = append(violations, ConstraintViolation{
+					Constraint: ConstraintDerbyWeek,
+					Week:       week,
+					Teams:      derby.Teams[:],
+					Detail:     fmt.Sprintf("%s v %s is not played in week %d", derby.Teams[0], derby.Teams[1], week),
+				})
+				cost += 3
+			}
+		}
+	}
+
+	return violations, cost
+}
+
+func blockedKindText(kind string) string {
+	if kind == BlockAll {
+		return "at all"
+	}
+	return "at home"
+}
+
+// schedulePlan is the search state of the solver: the order in which the
+// circle-method rounds are played in each half and which pairings are reversed
+type schedulePlan struct {
+	rounds   [][][2]string
+	first    []int
+	second   []int
+	reversed [][]bool
+}
+
+func newSchedulePlan(rounds [][][2]string) *schedulePlan {
+	plan := &schedulePlan{rounds: rounds}
+	for r := range rounds {
+		plan.first = append(plan.first, r)
+		plan.second = append(plan.second, r)
+		plan.reversed = append(plan.reversed, make([]bool, len(rounds[r])))
+	}
+	return plan
+}
+
+func (plan *schedulePlan) clone() *schedulePlan {
+	copied := &schedulePlan{
+		rounds: plan.rounds,
+		first:  append([]int{}, plan.first...),
+		second: append([]int{}, plan.second...),
+	}
+	for _, flags := range plan.reversed {
+		copied.reversed = append(copied.reversed, append([]bool{}, flags...))
+	}
+	return copied
+}
+
+func (plan *schedulePlan) shuffle(rng *rand.Rand) {
+	rng.Shuffle(len(plan.first), func(i, j int) { plan.first[i], plan.first[j] = plan.first[j], plan.first[i] })
+	rng.Shuffle(len(plan.second), func(i, j int) { plan.second[i], plan.second[j] = plan.second[j], plan.second[i] })
+	for _, flags := range plan.reversed {
+		for i := range flags {
+			flags[i] = rng.Intn(2) == 1
+		}
+	}
+}
+
+// mutate applies one random move and returns a function that undoes it
+func (plan *schedulePlan) mutate(rng *rand.Rand) func() {
+	n := len(plan.first)
+	switch move := rng.Intn(3); {
+	case move < 2 && n > 1:
+		order := plan.first
+		if move == 1 {
+			order = plan.second
+		}
+		i, j := rng.Intn(n), rng.Intn(n)
+		order[i], order[j] = order[j], order[i]
+		return func() { order[i], order[j] = order[j], order[i] }
+	default:
+		r := rng.Intn(n)
+		if len(plan.reversed[r]) == 0 {
+			return func() {}
+		}
+		p := rng.Intn(len(plan.reversed[r]))
+		plan.reversed[r][p] = !plan.reversed[r][p]
+		return func() { plan.reversed[r][p] = !plan.reversed[r][p] }
+	}
+}
+
+// fixtures turns the plan into unplayed matches numbered from week 1
+func (plan *schedulePlan) fixtures() []models.Match {
+	var fixtures []models.Match
+	for half, order := range [][]int{plan.first, plan.second} {
+		for slot, r := range order {
+			for p, pair := range plan.rounds[r] {
+				home, away := pair[0], pair[1]
+				if plan.reversed[r][p] != (half == 1) {
+					home, away = away, home
+				}
+				fixtures = append(fixtures, models.Match{
+					Week:     half*len(order) + slot + 1,
+					HomeTeam: home,
+					AwayTeam: away,
+				})
+			}
+		}
+	}
+	return fixtures
+}
@@ -0,0 +1,320 @@
+package league
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"leaguesimulator/db"
+	"leaguesimulator/models"
+)
+
+const (
+	settingCurrentSeason = "current_season"
+
+	SeasonSourceArchive    = "archive"
+	SeasonSourceHistorical = "historical_matches"
+	SeasonSourceImported   = "imported"
+	SeasonSourceCurrent    = "current"
+)
+
+// ErrInvalidSeason is returned when a new season cannot be started yet
+var ErrInvalidSeason = errors.New("invalid season")
+
+// ArchivedSeason is the final (or, for the running season, current) table of
+// one season. Source tells whether it was read from the archive, rebuilt from
+// historical_matches, rebuilt from an imported results file or taken from the
+// live league. Imported seasons only hold the matches between league teams,
+// so they are never complete and have no champion.
+type ArchivedSeason struct {
+	Season    int            `json:"season"`
+	Label     string         `json:"label,omitempty"`
+	Complete  bool           `json:"complete"`
+	Source    string         `json:"source"`
+	Champion  string         `json:"champion,omitempty"`
+	RunnerUp  string         `json:"runner_up,omitempty"`
+	Standings []TeamStanding `json:"standings"`
+}
+
+// AllTimeStanding is a team's combined record over every season
+type AllTimeStanding struct {
+	TeamStanding
+	Seasons    int `json:"seasons"`
+	Titles     int `json:"titles"`
+	RunnerUps  int `json:"runner_ups"`
+	BestFinish int `json:"best_finish"`
+}
+
+// ClubHonours lists the seasons in which a team won the league or finished second
+type ClubHonours struct {
+	Team      string `json:"team"`
+	Titles    []int  `json:"titles"`
+	RunnerUps []int  `json:"runner_ups"`
+}
+
+// loadCurrentSeason restores the number of the running season
+func (lm *LeagueManager) loadCurrentSeason() {
+	db.CurrentSeason = 1
+	if value, ok, err := db.GetLeagueSetting(settingCurrentSeason); err == nil && ok {
+		if season, err := strconv.Atoi(value); err == nil && season > 0 {
+			db.CurrentSeason = season
+		}
+	}
+}
+
+// archiveCurrentSeason stores the final table of the running season and
+// rewrites its historical matches from the stored results
+func (lm *LeagueManager) archiveCurrentSeason() error {
+	lm.updateStandings()
+	standings := make([]models.SeasonStanding, len(lm.Standings))
+	for i, standing := range lm.Standings {
+		standings[i] = models.SeasonStanding{
+			Season:       db.CurrentSeason,
+			Position:     i + 1,
+			Team:         standing.Name,
+			Played:       standing.Played,
+			Won:          standing.Won,
+			Drawn:        standing.Drawn,
+			Lost:         standing.Lost,
+			GoalsFor:     standing.GoalsFor,
+			GoalsAgainst: standing.GoalsAgainst,
+			Points:       standing.Points,
+		}
+	}
+	return db.ArchiveSeason(db.CurrentSeason, standings, lm.Matches)
+}
+
+// StartNewSeason archives the finished season and starts the next one with a
+// fresh set of fixtures for the same teams
+func (lm *LeagueManager) StartNewSeason() (*ArchivedSeason, error) {
+	if !lm.SeasonComplete() {
+		return nil, fmt.Errorf("%w: season %d is not finished yet", ErrInvalidSeason, db.CurrentSeason)
+	}
+	if lm.PlayoffCupID != 0 {
+		playoffs, err := lm.GetPlayoffStatus()
+		if err != nil {
+			return nil, err
+		}
+		if playoffs.Status != PlayoffStatusCompleted {
+			return nil, fmt.Errorf("%w: the playoffs of season %d are still running", ErrInvalidSeason, db.CurrentSeason)
+		}
+	}
+
+	if err := lm.archiveCurrentSeason(); err != nil {
+		return nil, err
+	}
+	finished := newArchivedSeason(db.CurrentSeason, true, SeasonSourceArchive, lm.GetStandings())
+
+	next := db.CurrentSeason + 1
+	if err := db.SaveLeagueSetting(settingCurrentSeason, strconv.Itoa(next)); err != nil {
+		return nil, err
+	}
+	db.CurrentSeason = next
+	lm.ResetLeague()
+	return &finished, nil
+}
+
+func newArchivedSeason(season int, complete bool, source string, standings []TeamStanding) ArchivedSeason {
+	archived := ArchivedSeason{Season: season, Complete: complete, Source: source, Standings: standings}
+	if complete && len(standings) > 0 {
+		archived.Champion = standings[0].Name
+		if len(standings) > 1 {
+			archived.RunnerUp = standings[1].Name
+		}
+	}
+	return archived
+}
+
+// GetSeasonArchive returns the table of every season: archived final tables,
+// tables rebuilt from historical_matches for seasons that were never archived
+// or were imported, and the running season from the live league
+func (lm *LeagueManager) GetSeasonArchive() ([]ArchivedSeason, error) {
+	stored, err := db.GetAllSeasonStandings()
+	if err != nil {
+		return nil, err
+	}
+	tables := make(map[int][]TeamStanding)
+	for _, row := range stored {
+		tables[row.Season] = append(tables[row.Season], TeamStanding{
+			Name:         row.Team,
+			Played:       row.Played,
+			Won:          row.Won,
+			Drawn:        row.Drawn,
+			Lost:         row.Lost,
+			GoalsFor:     row.GoalsFor,
+			GoalsAgainst: row.GoalsAgainst,
+			GoalDiff:     row.GoalsFor - row.GoalsAgainst,
+			Points:       row.Points,
+		})
+	}
+
+	imported, err := db.GetImportedSeasons()
+	if err != nil {
+		return nil, err
+	}
+	labels := make(map[int]string)
+	for _, season := range imported {
+		labels[season.Season] = season.Label
+	}
+
+	archive := []ArchivedSeason{}
+	matches, err := lm.archivedMatches()
+	if err != nil {
+		return nil, err
+	}
+	for _, seasonMatches := range splitSeasons(matches) {
+		season := seasonMatches[0].Season
+		if season == db.CurrentSeason {
+			continue
+		}
+		if label, ok := labels[season]; ok {
+			partial := newArchivedSeason(season, false, SeasonSourceImported, historicalTable(seasonMatches))
+			partial.Label = label
+			archive = append(archive, partial)
+			continue
+		}
+		if table, ok := tables[season]; ok {
+			archive = append(archive, newArchivedSeason(season, true, SeasonSourceArchive, table))
+			delete(tables, season)
+			continue
+		}
+		archive = append(archive, newArchivedSeason(season, true, SeasonSourceHistorical, historicalTable(seasonMatches)))
+	}
+	for season, table := range tables {
+		if season != db.CurrentSeason {
+			archive = append(archive, newArchivedSeason(season, true, SeasonSourceArchive, table))
+		}
+	}
+
+	archive = append(archive, newArchivedSeason(db.CurrentSeason, lm.SeasonComplete(), SeasonSourceCurrent, lm.GetStandings()))
+	sort.SliceStable(archive, func(i, j int) bool { return archive[i].Season < archive[j].Season })
+	return archive, nil
+}
+
+// historicalTable rebuilds a season's table from its archived matches
+func historicalTable(matches []models.HistoricalMatch) []TeamStanding {
+	var teamNames []string
+	seen := make(map[string]bool)
+	played := make([]models.Match, len(matches))
+	for i, match := range matches {
+		for _, team := range []string{match.HomeTeam, match.AwayTeam} {
+			if !seen[team] {
+				seen[team] = true
+				teamNames = append(teamNames, team)
+			}
+		}
+		played[i] = models.Match{
+			Week:      match.Week,
+			HomeTeam:  match.HomeTeam,
+			AwayTeam:  match.AwayTeam,
+			HomeGoals: match.HomeGoals,
+			AwayGoals: match.AwayGoals,
+			Played:    true,
+		}
+	}
+	return computeStandings(teamNames, played)
+}
+
+// GetAllTimeTable adds up every season, including the running one, into one
+// table ordered like a season table. Titles, runner-up and best finishes only
+// count for finished seasons, which leaves out imported ones.
+func (lm *LeagueManager) GetAllTimeTable() ([]AllTimeStanding, error) {
+	archive, err := lm.GetSeasonArchive()
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]*AllTimeStanding)
+	for _, season := range archive {
+		for position, standing := range season.Standings {
+			total := totals[standing.Name]
+			if total == nil {
+				total = &AllTimeStanding{TeamStanding: TeamStanding{Name: standing.Name}}
+				totals[standing.Name] = total
+			}
+			if standing.Played == 0 {
+				continue
+			}
+			total.Played += standing.Played
+			total.Won += standing.Won
+			total.Drawn += standing.Drawn
+			total.Lost += standing.Lost
+			total.GoalsFor += standing.GoalsFor
+			total.GoalsAgainst += standing.GoalsAgainst
+			total.Points += standing.Points
+			total.Seasons++
+			if !season.Complete {
+				continue
+			}
+			if total.BestFinish == 0 || position+1 < total.BestFinish {
+				total.BestFinish = position + 1
+			}
+			switch standing.Name {
+			case season.Champion:
+				total.Titles++
+			case season.RunnerUp:
+				total.RunnerUps++
+			}
+		}
+	}
+
+	// Order with the same tiebreakers as a season table
+	order := []TeamStanding{}
+	for _, total := range totals {
+		total.GoalDiff = total.GoalsFor - total.GoalsAgainst
+		order = append(order, total.TeamStanding)
+	}
+	sortStandings(order)
+
+	table := []AllTimeStanding{}
+	for _, standing := range order {
+		table = append(table, *totals[standing.Name])
+	}
+	return table, nil
+}
+
+// GetHonours lists every team's league titles and runner-up finishes, most
+// decorated first
+func (lm *LeagueManager) GetHonours() ([]ClubHonours, error) {
+	archive, err := lm.GetSeasonArchive()
+	if err != nil {
+		return nil, err
+	}
+
+	honours := make(map[string]*ClubHonours)
+	get := func(team string) *ClubHonours {
+		if honours[team] == nil {
+			honours[team] = &ClubHonours{Team: team, Titles: []int{}, RunnerUps: []int{}}
+		}
+		return honours[team]
+	}
+	for _, season := range archive {
+		for _, standing := range season.Standings {
+			get(standing.Name)
+		}
+		if season.Champion != "" {
+			club := get(season.Champion)
+			club.Titles = append(club.Titles, season.Season)
+		}
+		if season.RunnerUp != "" {
+			club := get(season.RunnerUp)
+			club.RunnerUps = append(club.RunnerUps, season.Season)
+		}
+	}
+
+	list := []ClubHonours{}
+	for _, club := range honours {
+		list = append(list, *club)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if len(list[i].Titles) != len(list[j].Titles) {
+			return len(list[i].Titles) > len(list[j].Titles)
+		}
+		if len(list[i].RunnerUps) != len(list[j].RunnerUps) {
+			return len(list[i].RunnerUps) > len(list[j].RunnerUps)
+		}
+		return list[i].Team < list[j].Team
+	})
+	return list, nil
+}
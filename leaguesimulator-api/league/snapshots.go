@@ -0,0 +1,281 @@
+package league
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"leaguesimulator/db"
+	"leaguesimulator/models"
+)
+
+const (
+	settingRandomSeed = "random_seed"
+
+	// snapshotVersion is bumped whenever SnapshotDocument changes shape.
+	// Version 2 added the season and version 3 the result edits of each match.
+	snapshotVersion = 3
+)
+
+// ErrInvalidSnapshot is returned when a snapshot cannot be created or restored
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+// ErrSnapshotNotFound is returned when no snapshot has the requested ID
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// SnapshotRules are the league settings saved with a snapshot
+type SnapshotRules struct {
+	Mode        string                `json:"mode"`
+	Playoffs    PlayoffConfig         `json:"playoffs"`
+	Calendar    CalendarConfig        `json:"calendar"`
+	Constraints SchedulingConstraints `json:"constraints"`
+}
+
+// SnapshotMatch is a stored match together with its scorers and its result
+// edits, oldest first
+type SnapshotMatch struct {
+	models.Match
+	Scorers []models.Goal      `json:"scorers"`
+	Edits   []models.MatchEdit `json:"edits,omitempty"`
+}
+
+// SnapshotDocument is the versioned JSON document stored for a snapshot
+type SnapshotDocument struct {
+	Version   int             `json:"version"`
+	Name      string          `json:"name"`
+	CreatedAt time.Time       `json:"created_at"`
+	Season    int             `json:"season"`
+	Week      int             `json:"week"`
+	Seed      int64           `json:"seed"`
+	Teams     []models.Team   `json:"teams"`
+	Matches   []SnapshotMatch `json:"matches"`
+	Rules     SnapshotRules   `json:"rules"`
+}
+
+// loadRandomSeed restores the league's random seed. Zero means every match is
+// drawn from a fresh time-based seed.
+func (lm *LeagueManager) loadRandomSeed() {
+	lm.Seed = 0
+	if value, ok, err := db.GetLeagueSetting(settingRandomSeed); err == nil && ok {
+		lm.Seed, _ = strconv.ParseInt(value, 10, 64)
+	}
+}
+
+// SetRandomSeed fixes the seed used to simulate matches, or clears it with zero
+func (lm *LeagueManager) SetRandomSeed(seed int64) error {
+	var err error
+	if seed == 0 {
+		err = db.DeleteLeagueSetting(settingRandomSeed)
+	} else {
+		err = db.SaveLeagueSetting(settingRandomSeed, strconv.FormatInt(seed, 10))
+	}
+	if err != nil {
+		return err
+	}
+	lm.Seed = seed
+	return nil
+}
+
+// matchRandom returns the source of random numbers for one match. With a
+// league seed every fixture gets its own repeatable stream, so a restored
+// snapshot replays the same results.
+func (lm *LeagueManager) matchRandom(week int, home, away string) func(int) int {
+	if lm.Seed == 0 {
+		return rand.Intn
+	}
+
+	hash := fnv.New64a()
+	fmt.Fprintf(hash, "%d:%d:%s:%s", lm.Seed, week, home, away)
+	return rand.New(rand.NewSource(int64(hash.Sum64()))).Intn
+}
+
+// CreateSnapshot saves the complete league state under a unique name
+func (lm *LeagueManager) CreateSnapshot(name string) (*models.Snapshot, error) {
+	if name == "" {
+		return nil, fmt.Errorf("%w: a name is required", ErrInvalidSnapshot)
+	}
+	existing, err := db.GetAllSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range existing {
+		if snapshot.Name == name {
+			return nil, fmt.Errorf("%w: a snapshot named %q already exists", ErrInvalidSnapshot, name)
+		}
+	}
+
+	document := SnapshotDocument{
+		Version:   snapshotVersion,
+		Name:      name,
+		CreatedAt: time.Now().UTC(),
+		Season:    db.CurrentSeason,
+		Week:      lm.Week,
+		Seed:      lm.Seed,
+		Teams:     append([]models.Team{}, lm.Teams...),
+		Matches:   []SnapshotMatch{},
+		Rules: SnapshotRules{
+			Mode:        lm.Mode,
+			Playoffs:    lm.Playoffs,
+			Calendar:    lm.Calendar,
+			Constraints: lm.Constraints,
+		},
+	}
+
+	for _, match := range lm.GetMatches() {
+		stored := SnapshotMatch{Match: match, Scorers: []models.Goal{}}
+		if match.Played {
+			if stored.Scorers, err = db.GetMatchGoals(match.ID); err != nil {
+				return nil, err
+			}
+			if stored.Edits, err = db.GetMatchEdits(match.ID); err != nil {
+				return nil, err
+			}
+		}
+		document.Matches = append(document.Matches, stored)
+	}
+
+	value, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := models.Snapshot{
+		Name:     name,
+		Version:  snapshotVersion,
+		Week:     lm.Week,
+		Document: value,
+	}
+	if snapshot.ID, err = db.CreateSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+	snapshot.CreatedAt = document.CreatedAt
+	snapshot.Document = nil
+	return &snapshot, nil
+}
+
+// ListSnapshots returns every saved snapshot without its document
+func ListSnapshots() ([]models.Snapshot, error) {
+	return db.GetAllSnapshots()
+}
+
+// GetSnapshot loads and decodes a snapshot
+func GetSnapshot(snapshotID int) (*models.Snapshot, *SnapshotDocument, error) {
+	snapshot, err := db.GetSnapshot(snapshotID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, fmt.Errorf("%w: snapshot %d", ErrSnapshotNotFound, snapshotID)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if snapshot.Version > snapshotVersion {
+		return nil, nil, fmt.Errorf("%w: snapshot version %d is newer than the supported version %d", ErrInvalidSnapshot, snapshot.Version, snapshotVersion)
+	}
+
+	var document SnapshotDocument
+	if err := json.Unmarshal(snapshot.Document, &document); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	return snapshot, &document, nil
+}
+
+// RestoreSnapshot replaces the current league with a saved snapshot. Any
+// running playoff bracket is dropped. Teams that are not in the snapshot are
+// removed, which deletes their past seasons, so a restore that drops teams
+// with stored history is refused unless removeTeams is set.
+func (lm *LeagueManager) RestoreSnapshot(snapshotID int, removeTeams bool) (*models.Snapshot, error) {
+	snapshot, document, err := GetSnapshot(snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	if len(document.Teams) == 0 {
+		return nil, fmt.Errorf("%w: snapshot %d has no teams", ErrInvalidSnapshot, snapshotID)
+	}
+	removal, err := lm.teamRemoval(document.Teams)
+	if err != nil {
+		return nil, err
+	}
+	if err := removal.check(removeTeams); err != nil {
+		return nil, err
+	}
+
+	// Venues may have been removed since the snapshot was taken
+	venues, err := db.GetAllVenues()
+	if err != nil {
+		return nil, err
+	}
+	knownVenues := make(map[int]bool)
+	for _, venue := range venues {
+		knownVenues[venue.ID] = true
+	}
+
+	teams := make([]models.Team, len(document.Teams))
+	for i, team := range document.Teams {
+		if !knownVenues[team.VenueID] {
+			team.VenueID = 0
+		}
+		teams[i] = team
+	}
+
+	matches := make([]models.Match, len(document.Matches))
+	scorers := make([][]models.Goal, len(document.Matches))
+	edits := make([][]models.MatchEdit, len(document.Matches))
+	for i, match := range document.Matches {
+		if !knownVenues[match.VenueID] {
+			match.VenueID = 0
+		}
+		matches[i] = match.Match
+		scorers[i] = match.Scorers
+		edits[i] = match.Edits
+	}
+
+	// Version 1 snapshots did not record their season; they restore into the running one
+	season := document.Season
+	if season == 0 {
+		season = db.CurrentSeason
+	}
+	settings := map[string]string{
+		settingPlayoffCup:    "",
+		settingRandomSeed:    "",
+		settingLeagueMode:    document.Rules.Mode,
+		settingCurrentSeason: strconv.Itoa(season),
+	}
+	if document.Seed != 0 {
+		settings[settingRandomSeed] = strconv.FormatInt(document.Seed, 10)
+	}
+	for name, rule := range map[string]interface{}{
+		settingPlayoffConfig:         document.Rules.Playoffs,
+		settingCalendarConfig:        document.Rules.Calendar,
+		settingSchedulingConstraints: document.Rules.Constraints,
+	} {
+		value, err := json.Marshal(rule)
+		if err != nil {
+			return nil, err
+		}
+		settings[name] = string(value)
+	}
+
+	if err := db.RestoreLeagueState(season, teams, matches, scorers, edits, settings); err != nil {
+		return nil, err
+	}
+
+	lm.InitLeague()
+	lm.Week = document.Week
+
+	snapshot.Document = nil
+	return snapshot, nil
+}
+
+// DeleteSnapshot removes a saved snapshot
+func DeleteSnapshot(snapshotID int) error {
+	err := db.DeleteSnapshot(snapshotID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: snapshot %d", ErrSnapshotNotFound, snapshotID)
+	}
+	return err
+}
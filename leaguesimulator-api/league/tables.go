@@ -0,0 +1,129 @@
+package league
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"leaguesimulator/models"
+)
+
+const (
+	VenueHome = "home"
+	VenueAway = "away"
+
+	HalfFirst  = "first"
+	HalfSecond = "second"
+)
+
+// ErrInvalidTable is returned when a table variant is requested with unknown options
+var ErrInvalidTable = errors.New("invalid table")
+
+// TableOptions selects a variant of the league table. Zero values mean no
+// filter: Week limits the matches to those up to that week, Venue keeps only
+// home or away matches, Half keeps one half of the season and Form keeps each
+// team's last Form matches after the other filters.
+type TableOptions struct {
+	Week  int    `json:"week,omitempty"`
+	Venue string `json:"venue,omitempty"`
+	Half  string `json:"half,omitempty"`
+	Form  int    `json:"form,omitempty"`
+}
+
+// GetTable builds a table variant from the stored matches, ordered with the
+// same tiebreakers as the full table
+func (lm *LeagueManager) GetTable(options TableOptions) ([]TeamStanding, error) {
+	matches := lm.Matches
+	if options.Week != 0 {
+		if options.Week < 0 || options.Week > lm.TotalWeeks() {
+			return nil, fmt.Errorf("%w: week must be between 1 and %d", ErrInvalidTable, lm.TotalWeeks())
+		}
+		matches = matchesUpToWeek(matches, options.Week)
+	}
+
+	switch options.Venue {
+	case "", VenueHome, VenueAway:
+	default:
+		return nil, fmt.Errorf("%w: venue must be %q or %q", ErrInvalidTable, VenueHome, VenueAway)
+	}
+	if options.Form < 0 {
+		return nil, fmt.Errorf("%w: form must be a positive number of matches", ErrInvalidTable)
+	}
+
+	firstHalfWeeks := lm.firstHalfWeeks()
+	switch options.Half {
+	case "":
+	case HalfFirst, HalfSecond:
+		selected := []models.Match{}
+		for _, match := range matches {
+			if (match.Week <= firstHalfWeeks) == (options.Half == HalfFirst) {
+				selected = append(selected, match)
+			}
+		}
+		matches = selected
+	default:
+		return nil, fmt.Errorf("%w: half must be %q or %q", ErrInvalidTable, HalfFirst, HalfSecond)
+	}
+
+	counts := func(i int, team string) bool {
+		switch options.Venue {
+		case VenueHome:
+			return matches[i].HomeTeam == team
+		case VenueAway:
+			return matches[i].AwayTeam == team
+		}
+		return true
+	}
+
+	include := counts
+	if options.Form > 0 {
+		recent := lastMatches(matches, options.Form, counts)
+		include = func(i int, team string) bool { return recent[team][i] }
+	}
+
+	return computeTable(lm.teamNames(), matches, include), nil
+}
+
+// firstHalfWeeks is the number of weeks in the first half of the stored
+// double round robin: one round robin of the teams that have fixtures. The
+// team list can change after the schedule was made and postponed fixtures can
+// move past the last week, so neither decides the halfway point.
+func (lm *LeagueManager) firstHalfWeeks() int {
+	var teams []string
+	seen := make(map[string]bool)
+	for _, match := range lm.Matches {
+		for _, team := range []string{match.HomeTeam, match.AwayTeam} {
+			if !seen[team] {
+				seen[team] = true
+				teams = append(teams, team)
+			}
+		}
+	}
+	return len(roundRobin(teams))
+}
+
+// lastMatches picks, for every team, the indexes of its last n played and
+// counted matches, most recent week first
+func lastMatches(matches []models.Match, n int, counts func(i int, team string) bool) map[string]map[int]bool {
+	order := make([]int, len(matches))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return matches[order[a]].Week > matches[order[b]].Week })
+
+	recent := make(map[string]map[int]bool)
+	for _, i := range order {
+		if !matches[i].Played {
+			continue
+		}
+		for _, team := range []string{matches[i].HomeTeam, matches[i].AwayTeam} {
+			if recent[team] == nil {
+				recent[team] = make(map[int]bool)
+			}
+			if len(recent[team]) < n && counts(i, team) {
+				recent[team][i] = true
+			}
+		}
+	}
+	return recent
+}
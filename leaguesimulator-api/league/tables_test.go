@@ -0,0 +1,56 @@
+package league
+
+import (
+	"testing"
+
+	"leaguesimulator/models"
+)
+
+func TestFirstHalfWeeks(t *testing.T) {
+	// fixtures builds a double round robin, moving its first fixtures to the given weeks
+	fixtures := func(teams []string, moved ...int) []models.Match {
+		matches := doubleRoundRobin(teams)
+		for i, week := range moved {
+			matches[i].Week = week
+		}
+		return matches
+	}
+
+	tests := []struct {
+		name    string
+		matches []models.Match
+		teams   []models.Team
+		want    int
+	}{
+		{
+			name:    "four teams",
+			matches: fixtures([]string{"Lions", "Tigers", "Bears", "Wolves"}),
+			want:    3,
+		},
+		{
+			name:    "five teams rest in turn",
+			matches: fixtures([]string{"Lions", "Tigers", "Bears", "Wolves", "Eagles"}),
+			want:    5,
+		},
+		{
+			name:    "postponed fixtures moved past the last week",
+			matches: fixtures([]string{"Lions", "Tigers", "Bears", "Wolves"}, 7, 8),
+			want:    3,
+		},
+		{
+			name:    "team added after the schedule was made",
+			matches: fixtures([]string{"Lions", "Tigers", "Bears", "Wolves"}),
+			teams:   []models.Team{{Name: "Lions"}, {Name: "Tigers"}, {Name: "Bears"}, {Name: "Wolves"}, {Name: "Eagles"}, {Name: "Hawks"}},
+			want:    3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lm := &LeagueManager{Teams: test.teams, Matches: test.matches}
+			if got := lm.firstHalfWeeks(); got != test.want {
+				t.Errorf("firstHalfWeeks = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,416 @@
+package league
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"leaguesimulator/db"
+	"leaguesimulator/models"
+)
+
+const (
+	TournamentStatusAwaitingDraw = "awaiting_draw"
+	TournamentStatusGroupStage   = "group_stage"
+	TournamentStatusKnockout     = "knockout"
+	TournamentStatusCompleted    = "completed"
+)
+
+// ErrInvalidTournament is returned when a tournament request breaks the competition rules
+var ErrInvalidTournament = errors.New("invalid tournament operation")
+
+type GroupTable struct {
+	Group     string         `json:"group"`
+	Standings []TeamStanding `json:"standings"`
+	Qualified []string       `json:"qualified"`
+	Completed bool           `json:"completed"`
+}
+
+type TournamentView struct {
+	Tournament  *models.Tournament `json:"tournament"`
+	GroupTables []GroupTable       `json:"group_tables"`
+	Qualifiers  []string           `json:"qualifiers"`
+	Knockout    []CupRound         `json:"knockout"`
+}
+
+type TournamentRound struct {
+	Stage    string                   `json:"stage"`
+	Matchday int                      `json:"matchday,omitempty"`
+	Matches  []models.TournamentMatch `json:"matches,omitempty"`
+	Ties     []models.CupTie          `json:"ties,omitempty"`
+}
+
+// CreateTournament registers a group stage plus knockout tournament. Entrants
+// are ranked by strength, which decides the seeding pots at the draw.
+func CreateTournament(name string, teamNames []string, groupCount, qualifiersPerGroup, extraQualifiers, knockoutLegs int) (*models.Tournament, error) {
+	teams, err := db.GetAllTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	entrants, err := selectTeams(teams, teamNames)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTournament, err)
+	}
+
+	if knockoutLegs == 0 {
+		knockoutLegs = 1
+	}
+	if qualifiersPerGroup == 0 {
+		qualifiersPerGroup = 2
+	}
+	if err := validateTournament(len(entrants), groupCount, qualifiersPerGroup, extraQualifiers, knockoutLegs); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entrants, func(i, j int) bool {
+		return entrants[i].Strength > entrants[j].Strength
+	})
+
+	tournament := models.Tournament{
+		Name:               name,
+		GroupCount:         groupCount,
+		QualifiersPerGroup: qualifiersPerGroup,
+		ExtraQualifiers:    extraQualifiers,
+		KnockoutLegs:       knockoutLegs,
+		Status:             TournamentStatusAwaitingDraw,
+		Groups:             []models.TournamentGroup{},
+		Matches:            []models.TournamentMatch{},
+	}
+	for _, team := range entrants {
+		tournament.Entrants = append(tournament.Entrants, team.Name)
+	}
+
+	id, err := db.CreateTournament(tournament)
+	if err != nil {
+		return nil, err
+	}
+	tournament.ID = id
+
+	return &tournament, nil
+}
+
+// validateTournament checks that the groups can be filled and the qualification rules are satisfiable
+func validateTournament(teams, groupCount, qualifiersPerGroup, extraQualifiers, knockoutLegs int) error {
+	if groupCount < 1 {
+		return fmt.Errorf("%w: at least one group is required", ErrInvalidTournament)
+	}
+	if groupCount > 26 {
+		return fmt.Errorf("%w: at most 26 groups are supported", ErrInvalidTournament)
+	}
+	smallestGroup := teams / groupCount
+	if smallestGroup < 2 {
+		return fmt.Errorf("%w: %d teams cannot fill %d groups of at least 2", ErrInvalidTournament, teams, groupCount)
+	}
+	if qualifiersPerGroup < 1 || qualifiersPerGroup > smallestGroup {
+		return fmt.Errorf("%w: qualifiers per group must be between 1 and %d", ErrInvalidTournament, smallestGroup)
+	}
+	if extraQualifiers < 0 || extraQualifiers >= groupCount {
+		return fmt.Errorf("%w: extra qualifiers must be between 0 and %d", ErrInvalidTournament, groupCount-1)
+	}
+	if extraQualifiers > 0 && qualifiersPerGroup == smallestGroup {
+		return fmt.Errorf("%w: no teams are left in the smallest group for extra qualifiers", ErrInvalidTournament)
+	}
+	if groupCount*qualifiersPerGroup+extraQualifiers < 2 {
+		return fmt.Errorf("%w: the knockout stage needs at least 2 qualifiers", ErrInvalidTournament)
+	}
+	if knockoutLegs != 1 && knockoutLegs != 2 {
+		return fmt.Errorf("%w: knockout legs must be 1 or 2", ErrInvalidTournament)
+	}
+	return nil
+}
+
+// ListTournaments returns every tournament without groups or matches
+func ListTournaments() ([]models.Tournament, error) {
+	return db.GetAllTournaments()
+}
+
+// GetTournament loads a tournament with its groups and group matches
+func GetTournament(tournamentID int) (*models.Tournament, error) {
+	return db.GetTournament(tournamentID)
+}
+
+// DrawTournamentGroups splits the entrants into seeding pots of one team per
+// group and draws one team from each pot into every group, then schedules
+// a single round-robin inside each group
+func DrawTournamentGroups(tournament *models.Tournament) error {
+	if tournament.Status != TournamentStatusAwaitingDraw {
+		return fmt.Errorf("%w: groups have already been drawn", ErrInvalidTournament)
+	}
+
+	groups := make([]models.TournamentGroup, tournament.GroupCount)
+	for i := range groups {
+		groups[i].Name = string(rune('A' + i))
+	}
+
+	for start := 0; start < len(tournament.Entrants); start += tournament.GroupCount {
+		end := start + tournament.GroupCount
+		if end > len(tournament.Entrants) {
+			end = len(tournament.Entrants)
+		}
+		pot := append([]string{}, tournament.Entrants[start:end]...)
+		rand.Shuffle(len(pot), func(i, j int) { pot[i], pot[j] = pot[j], pot[i] })
+
+		// A partial last pot goes into randomly chosen groups
+		groupOrder := rand.Perm(tournament.GroupCount)
+		for i, teamName := range pot {
+			group := &groups[groupOrder[i]]
+			group.Teams = append(group.Teams, teamName)
+		}
+	}
+
+	var matches []models.TournamentMatch
+	for _, group := range groups {
+		for round, pairs := range roundRobin(group.Teams) {
+			for _, pair := range pairs {
+				match := models.TournamentMatch{
+					TournamentID: tournament.ID,
+					Group:        group.Name,
+					Matchday:     round + 1,
+					HomeTeam:     pair[0],
+					AwayTeam:     pair[1],
+				}
+				matches = append(matches, match)
+			}
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Matchday < matches[j].Matchday
+	})
+
+	drawn := *tournament
+	drawn.Groups = groups
+	drawn.Matches = matches
+	drawn.Status = TournamentStatusGroupStage
+	drawn.CurrentMatchday = 0
+	if err := db.SaveTournamentDraw(&drawn); err != nil {
+		return err
+	}
+	*tournament = drawn
+	return nil
+}
+
+// PlayTournamentRound plays the next group matchday or, once the groups are
+// finished, the current knockout round followed by the draw of the next one
+func PlayTournamentRound(tournament *models.Tournament) (*TournamentRound, error) {
+	switch tournament.Status {
+	case TournamentStatusAwaitingDraw:
+		return nil, fmt.Errorf("%w: groups have not been drawn yet", ErrInvalidTournament)
+	case TournamentStatusCompleted:
+		return nil, fmt.Errorf("%w: tournament is already completed", ErrInvalidTournament)
+	case TournamentStatusKnockout:
+		return playTournamentKnockoutRound(tournament)
+	}
+
+	teams, err := db.GetAllTeams()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*models.Team)
+	for i := range teams {
+		byName[teams[i].Name] = &teams[i]
+	}
+
+	matchday := tournament.CurrentMatchday + 1
+	round := &TournamentRound{Stage: TournamentStatusGroupStage, Matchday: matchday}
+	remaining := 0
+
+	played := *tournament
+	played.Matches = append([]models.TournamentMatch{}, tournament.Matches...)
+	for i := range played.Matches {
+		match := &played.Matches[i]
+		if match.Played {
+			continue
+		}
+		if match.Matchday != matchday {
+			remaining++
+			continue
+		}
+
+		home, away := byName[match.HomeTeam], byName[match.AwayTeam]
+		if home == nil || away == nil {
+			return nil, fmt.Errorf("team missing for match %s vs %s", match.HomeTeam, match.AwayTeam)
+		}
+		match.HomeGoals, match.AwayGoals = simulateScore(home, away)
+		match.Played = true
+		round.Matches = append(round.Matches, *match)
+	}
+
+	played.CurrentMatchday = matchday
+	var knockout *models.Cup
+	if remaining == 0 {
+		if knockout, err = drawTournamentKnockout(&played); err != nil {
+			return nil, err
+		}
+	}
+	if err := db.SaveTournamentRound(&played, matchday, knockout, 0); err != nil {
+		return nil, err
+	}
+
+	*tournament = played
+	return round, nil
+}
+
+// drawTournamentKnockout seeds the group qualifiers into a new knockout cup
+// and draws its first round. The cup is stored together with the matchday.
+func drawTournamentKnockout(tournament *models.Tournament) (*models.Cup, error) {
+	tables := GroupTables(tournament)
+	qualifiers := tournamentQualifiers(tournament, tables)
+
+	cup := newCup(tournament.Name+" Knockout", qualifiers, tournament.KnockoutLegs, DrawSeeded)
+	if err := drawCupRound(&cup); err != nil {
+		return nil, err
+	}
+
+	tournament.Status = TournamentStatusKnockout
+	return &cup, nil
+}
+
+func playTournamentKnockoutRound(tournament *models.Tournament) (*TournamentRound, error) {
+	stored, err := db.GetCup(tournament.CupID)
+	if err != nil {
+		return nil, err
+	}
+
+	cup := copyCup(stored)
+	playedRound := cup.CurrentRound
+	if err := playCupRound(&cup); err != nil {
+		return nil, err
+	}
+	ties := cupRoundTies(&cup, playedRound)
+
+	played := *tournament
+	if cup.Status == CupStatusCompleted {
+		played.Status = TournamentStatusCompleted
+		played.Champion = cup.Champion
+	} else if err := drawCupRound(&cup); err != nil {
+		return nil, err
+	}
+
+	if err := db.SaveTournamentRound(&played, 0, &cup, playedRound); err != nil {
+		return nil, err
+	}
+
+	*tournament = played
+	return &TournamentRound{Stage: TournamentStatusKnockout, Ties: ties}, nil
+}
+
+// GroupTables builds the table of every group from its played matches
+func GroupTables(tournament *models.Tournament) []GroupTable {
+	tables := []GroupTable{}
+	for _, group := range tournament.Groups {
+		var played []models.Match
+		completed := true
+		for _, match := range tournament.Matches {
+			if match.Group != group.Name {
+				continue
+			}
+			if !match.Played {
+				completed = false
+				continue
+			}
+			played = append(played, models.Match{
+				HomeTeam:  match.HomeTeam,
+				AwayTeam:  match.AwayTeam,
+				HomeGoals: match.HomeGoals,
+				AwayGoals: match.AwayGoals,
+				Played:    true,
+			})
+		}
+
+		table := GroupTable{
+			Group:     group.Name,
+			Standings: computeStandings(group.Teams, played),
+			Qualified: []string{},
+			Completed: completed,
+		}
+		if completed {
+			for i := 0; i < tournament.QualifiersPerGroup && i < len(table.Standings); i++ {
+				table.Qualified = append(table.Qualified, table.Standings[i].Name)
+			}
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+// tournamentQualifiers lists the knockout entrants in seed order: group
+// winners first, then runners-up and so on, each tier ranked on the table
+// tiebreakers, followed by the best extra qualifiers from the next position.
+func tournamentQualifiers(tournament *models.Tournament, tables []GroupTable) []string {
+	groupOf := make(map[string]string)
+	var qualifiers []string
+
+	for position := 0; position <= tournament.QualifiersPerGroup; position++ {
+		var tier []TeamStanding
+		for _, table := range tables {
+			if position < len(table.Standings) {
+				tier = append(tier, table.Standings[position])
+				groupOf[table.Standings[position].Name] = table.Group
+			}
+		}
+		sortStandings(tier)
+
+		if position == tournament.QualifiersPerGroup {
+			if len(tier) > tournament.ExtraQualifiers {
+				tier = tier[:tournament.ExtraQualifiers]
+			}
+		}
+		for _, standing := range tier {
+			qualifiers = append(qualifiers, standing.Name)
+		}
+	}
+
+	avoidGroupRematches(qualifiers, groupOf)
+	return qualifiers
+}
+
+// avoidGroupRematches swaps lower seeds so that, where possible, no first
+// round knockout tie is a repeat of a group match. It only applies to full
+// brackets, where seed i always meets seed n+1-i.
+func avoidGroupRematches(seeds []string, groupOf map[string]string) {
+	n := len(seeds)
+	if n < 4 || bracketSize(n) != n {
+		return
+	}
+
+	for i := 0; i < n/2; i++ {
+		opponent := n - 1 - i
+		if groupOf[seeds[i]] != groupOf[seeds[opponent]] {
+			continue
+		}
+		for j := n / 2; j < n; j++ {
+			if j == opponent {
+				continue
+			}
+			partner := n - 1 - j
+			if groupOf[seeds[j]] != groupOf[seeds[i]] && groupOf[seeds[opponent]] != groupOf[seeds[partner]] {
+				seeds[j], seeds[opponent] = seeds[opponent], seeds[j]
+				break
+			}
+		}
+	}
+}
+
+// GetTournamentView combines the group tables, qualifiers and knockout bracket
+func GetTournamentView(tournament *models.Tournament) (*TournamentView, error) {
+	view := &TournamentView{
+		Tournament:  tournament,
+		GroupTables: GroupTables(tournament),
+		Qualifiers:  []string{},
+		Knockout:    []CupRound{},
+	}
+
+	if tournament.CupID != 0 {
+		cup, err := db.GetCup(tournament.CupID)
+		if err != nil {
+			return nil, err
+		}
+		view.Qualifiers = cup.Entrants
+		view.Knockout = CupBracket(cup)
+	}
+
+	return view, nil
+}
@@ -8,6 +8,14 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		db.InitDB()
+		if err := runCommand(os.Args[1:]); err != nil {
+			log.Fatalf("%s failed: %v", os.Args[1], err)
+		}
+		return
+	}
+
 	log.Println("Starting Football League Simulator...")
 	db.InitDB()
 	log.Println("Database connection is successful.")
@@ -16,7 +24,8 @@ func main() {
 	log.Println("  GET /api/info - API information")
 	log.Println("  POST /init-league - Initialize the league")
 	log.Println("  POST /next-week - Play next week matches")
-	log.Println("  GET /standings - Get current standings")
+	log.Println("  GET /standings - Get current standings with clinch and elimination status (?top=N, ?week=N, ?venue=home|away, ?half=first|second, ?form=N)")
+	log.Println("  GET /standings/history - Get the table after every week and each team's position timeline")
 	log.Println("  GET /matches - Get all matches")
 	log.Println("  GET /predict - Get predictions")
 	log.Println("  GET /predict/:team1/:team2 - Get specific match prediction")
@@ -28,7 +37,64 @@ func main() {
 	log.Println("  POST /reset - Reset the league")
 	log.Println("  GET /team/:name/analysis - Get team analysis")
 	log.Println("  GET /league-stats - Get league statistics")
-	log.Println("  GET /head-to-head/:team1/:team2 - Get head-to-head comparison")
+	log.Println("  GET /head-to-head/:team1/:team2 - Get all-time head-to-head (?from_season, ?to_season, ?venue)")
+	log.Println("  GET /records - Get streaks and match records per season and all-time (?season=N)")
+	log.Println("  GET /seasons - Get the final table of every season")
+	log.Println("  POST /seasons/new - Archive the finished season and start the next one")
+	log.Println("  GET /all-time-table - Get the combined table of every season")
+	log.Println("  GET /honours - Get league titles and runner-up finishes per club")
+	log.Println("  POST /import/teams - Replace the teams from a CSV or JSON body and generate fixtures")
+	log.Println("  POST /import - Replace the teams and fixtures from uploaded CSV or JSON files")
+	log.Println("  POST /import/history - Load football-data style results CSV files as historical seasons")
+	log.Println("  GET /team-aliases - List the team names used by imported results files")
+	log.Println("  PUT/DELETE /team-aliases/:alias - Map a results file team name to a league team, or remove it")
+	log.Println("  POST /ratings/fit - Fit attack, defence and home advantage to past results (\"apply\" writes strengths)")
+	log.Println("  GET /ratings - Get the last applied rating fit")
+	log.Println("  GET /predict/:team1/:team2/markets - Fair odds for 1X2, over/under, both teams to score, Asian handicap and correct scores")
+	log.Println("  POST /backtests - Replay stored seasons week by week and save accuracy, Brier, log loss and calibration reports")
+	log.Println("  GET /backtests - List saved backtest reports")
+	log.Println("  GET /backtests/:id - Get a backtest report with per-season scores and calibration")
+	log.Println("  GET /export/season - Download standings, results and fixtures as a zip of CSV files")
+	log.Println("  GET/POST /cups - List or create knockout cups")
+	log.Println("  GET /cups/:id - Get cup bracket")
+	log.Println("  POST /cups/:id/draw - Draw the next cup round")
+	log.Println("  POST /cups/:id/play-round - Play the drawn cup round")
+	log.Println("  GET/POST /tournaments - List or create group stage tournaments")
+	log.Println("  GET /tournaments/:id - Get tournament groups, tables and bracket")
+	log.Println("  POST /tournaments/:id/draw - Draw the tournament groups")
+	log.Println("  POST /tournaments/:id/play-round - Play the next matchday or knockout round")
+	log.Println("  GET/POST /divisions - List or create pyramid divisions")
+	log.Println("  POST /divisions/play-season - Play the season in every division")
+	log.Println("  GET /divisions/tables - Get division tables")
+	log.Println("  POST /divisions/rollover - Apply promotion and relegation")
+	log.Println("  GET /divisions/movements - Get promoted and relegated teams")
+	log.Println("  GET /playoffs - Get playoff bracket and champion")
+	log.Println("  PUT /playoffs/config - Configure the end-of-season playoff")
+	log.Println("  POST /playoffs/play-round - Play the current playoff round")
+	log.Println("  GET/POST /venues - List or create venues")
+	log.Println("  PUT /team/:name/venue - Assign a team's home venue")
+	log.Println("  GET/PUT /calendar/config - Get or change matchdays and kick-off times")
+	log.Println("  GET /calendar.ics - Export all fixtures as iCalendar")
+	log.Println("  GET /team/:name/calendar.ics - Export a team's fixtures as iCalendar")
+	log.Println("  GET/PUT /schedule/constraints - Get or change fixture scheduling constraints")
+	log.Println("  GET /schedule/check - Check fixtures against the scheduling constraints")
+	log.Println("  POST /schedule/generate - Regenerate constrained fixtures before the season starts")
+	log.Println("  GET /postponements - List postponed fixtures awaiting a new date")
+	log.Println("  POST /matches/:id/postpone - Postpone a fixture with a reason")
+	log.Println("  POST /matches/:id/reschedule - Move a postponed fixture to a later week or date")
+	log.Println("  GET/PUT /league/mode - Switch between simulated and manual results")
+	log.Println("  PUT /matches/:id/result - Enter a match result with scorers (manual mode)")
+	log.Println("  GET /matches/:id/scorers - Get the scorers of a match")
+	log.Println("  GET/PATCH /matches/:id - Get or correct a match by its ID")
+	log.Println("  GET /matches/:id/history - Get the audit log of result changes")
+	log.Println("  POST /matches/:id/revert - Restore the score before an earlier edit")
+	log.Println("  POST /rewind - Undo every result after a given week")
+	log.Println("  POST /undo-week - Undo the last played week")
+	log.Println("  GET/POST /snapshots - List or create league snapshots")
+	log.Println("  GET/DELETE /snapshots/:id - Get or delete a snapshot")
+	log.Println("  POST /snapshots/:id/restore - Restore the league from a snapshot")
+	log.Println("  GET/PUT /league/seed - Get or fix the random seed of match simulation")
+	log.Println("  POST /what-if - Standings and title odds for hypothetical results")
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -1,5 +1,10 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type Team struct {
 	Name         string `json:"name"`
 	Points       int    `json:"points"`
@@ -10,16 +15,67 @@ type Team struct {
 	GoalsFor     int    `json:"goals_for"`
 	GoalsAgainst int    `json:"goals_against"`
 	Strength     int    `json:"strength"`
+	VenueID      int    `json:"venue_id,omitempty"`
 }
 
 type Match struct {
-	ID        int    `json:"id"`
-	Week      int    `json:"week"`
-	HomeTeam  string `json:"home_team"`
-	AwayTeam  string `json:"away_team"`
-	HomeGoals int    `json:"home_goals"`
-	AwayGoals int    `json:"away_goals"`
-	Played    bool   `json:"played"`
+	ID                 int        `json:"id"`
+	Week               int        `json:"week"`
+	HomeTeam           string     `json:"home_team"`
+	AwayTeam           string     `json:"away_team"`
+	HomeGoals          int        `json:"home_goals"`
+	AwayGoals          int        `json:"away_goals"`
+	Played             bool       `json:"played"`
+	KickOff            *time.Time `json:"kick_off,omitempty"`
+	VenueID            int        `json:"venue_id,omitempty"`
+	Venue              string     `json:"venue,omitempty"`
+	Postponed          bool       `json:"postponed"`
+	PostponementReason string     `json:"postponement_reason,omitempty"`
+}
+
+// Goal is one scorer of a match. An own goal is scored by a player of Team
+// and counts for the opponent.
+type Goal struct {
+	ID      int    `json:"id"`
+	MatchID int    `json:"match_id"`
+	Team    string `json:"team"`
+	Player  string `json:"player"`
+	Minute  int    `json:"minute,omitempty"`
+	OwnGoal bool   `json:"own_goal"`
+	Penalty bool   `json:"penalty"`
+}
+
+// MatchEdit is one change of a match result in the audit log
+type MatchEdit struct {
+	ID           int       `json:"id"`
+	MatchID      int       `json:"match_id"`
+	OldHomeGoals int       `json:"old_home_goals"`
+	OldAwayGoals int       `json:"old_away_goals"`
+	NewHomeGoals int       `json:"new_home_goals"`
+	NewAwayGoals int       `json:"new_away_goals"`
+	Reason       string    `json:"reason"`
+	Actor        string    `json:"actor"`
+	RevertOf     int       `json:"revert_of,omitempty"`
+	EditedAt     time.Time `json:"edited_at"`
+}
+
+// Snapshot is a saved copy of the whole league state. Document holds the
+// versioned JSON and is only filled in when a single snapshot is loaded.
+type Snapshot struct {
+	ID        int             `json:"id"`
+	Name      string          `json:"name"`
+	Version   int             `json:"version"`
+	Week      int             `json:"week"`
+	CreatedAt time.Time       `json:"created_at"`
+	Document  json.RawMessage `json:"document,omitempty"`
+}
+
+type Venue struct {
+	ID       int      `json:"id"`
+	Name     string   `json:"name"`
+	City     string   `json:"city"`
+	Capacity int      `json:"capacity"`
+	Teams    []string `json:"teams"`
 }
 
 type Prediction struct {
@@ -38,3 +94,155 @@ type HistoricalMatch struct {
 	HomeGoals int    `json:"home_goals"`
 	AwayGoals int    `json:"away_goals"`
 }
+
+// SeasonStanding is one row of an archived final league table
+type SeasonStanding struct {
+	Season       int    `json:"season"`
+	Position     int    `json:"position"`
+	Team         string `json:"team"`
+	Played       int    `json:"played"`
+	Won          int    `json:"won"`
+	Drawn        int    `json:"drawn"`
+	Lost         int    `json:"lost"`
+	GoalsFor     int    `json:"goals_for"`
+	GoalsAgainst int    `json:"goals_against"`
+	Points       int    `json:"points"`
+}
+
+// ImportedSeason is a season loaded from a real results file. Label gives
+// its years, such as "2019/20".
+type ImportedSeason struct {
+	Season    int       `json:"season"`
+	Label     string    `json:"label"`
+	Matches   int       `json:"matches"`
+	Weeks     int       `json:"weeks"`
+	FirstDate time.Time `json:"first_date"`
+	LastDate  time.Time `json:"last_date"`
+}
+
+// TeamAlias maps a name used by an imported results file to a league team
+type TeamAlias struct {
+	Alias string `json:"alias"`
+	Team  string `json:"team"`
+}
+
+// BacktestReport is a saved prediction backtest with its headline figures.
+// Document holds the full report and is only filled in when one report is loaded.
+type BacktestReport struct {
+	ID          int             `json:"id"`
+	Name        string          `json:"name"`
+	Model       string          `json:"model"`
+	Predictions int             `json:"predictions"`
+	Accuracy    float64         `json:"accuracy"`
+	BrierScore  float64         `json:"brier_score"`
+	LogLoss     float64         `json:"log_loss"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Document    json.RawMessage `json:"document,omitempty"`
+}
+
+type Cup struct {
+	ID           int      `json:"id"`
+	Name         string   `json:"name"`
+	Legs         int      `json:"legs"`
+	DrawMethod   string   `json:"draw_method"`
+	Entrants     []string `json:"entrants"`
+	CurrentRound int      `json:"current_round"`
+	Status       string   `json:"status"`
+	Champion     string   `json:"champion,omitempty"`
+	Ties         []CupTie `json:"ties"`
+}
+
+// CupTie is one pairing of a knockout round. HomeTeam hosts the first leg;
+// ExtraTime and Penalties are given from HomeTeam's point of view.
+type CupTie struct {
+	ID        int        `json:"id"`
+	CupID     int        `json:"cup_id"`
+	Round     int        `json:"round"`
+	Slot      int        `json:"slot"`
+	HomeTeam  string     `json:"home_team"`
+	AwayTeam  string     `json:"away_team,omitempty"`
+	Bye       bool       `json:"bye"`
+	Legs      []CupLeg   `json:"legs"`
+	ExtraTime *ScoreLine `json:"extra_time,omitempty"`
+	Penalties *ScoreLine `json:"penalties,omitempty"`
+	Winner    string     `json:"winner,omitempty"`
+	Played    bool       `json:"played"`
+}
+
+type CupLeg struct {
+	HomeTeam  string `json:"home_team"`
+	AwayTeam  string `json:"away_team"`
+	HomeGoals int    `json:"home_goals"`
+	AwayGoals int    `json:"away_goals"`
+}
+
+type ScoreLine struct {
+	Home int `json:"home"`
+	Away int `json:"away"`
+}
+
+type Tournament struct {
+	ID                 int               `json:"id"`
+	Name               string            `json:"name"`
+	GroupCount         int               `json:"group_count"`
+	QualifiersPerGroup int               `json:"qualifiers_per_group"`
+	ExtraQualifiers    int               `json:"extra_qualifiers"`
+	KnockoutLegs       int               `json:"knockout_legs"`
+	Status             string            `json:"status"`
+	CurrentMatchday    int               `json:"current_matchday"`
+	CupID              int               `json:"cup_id,omitempty"`
+	Champion           string            `json:"champion,omitempty"`
+	Entrants           []string          `json:"entrants"`
+	Groups             []TournamentGroup `json:"groups"`
+	Matches            []TournamentMatch `json:"matches"`
+}
+
+type TournamentGroup struct {
+	Name  string   `json:"name"`
+	Teams []string `json:"teams"`
+}
+
+type TournamentMatch struct {
+	ID           int    `json:"id"`
+	TournamentID int    `json:"tournament_id"`
+	Group        string `json:"group"`
+	Matchday     int    `json:"matchday"`
+	HomeTeam     string `json:"home_team"`
+	AwayTeam     string `json:"away_team"`
+	HomeGoals    int    `json:"home_goals"`
+	AwayGoals    int    `json:"away_goals"`
+	Played       bool   `json:"played"`
+}
+
+type Division struct {
+	ID              int      `json:"id"`
+	Name            string   `json:"name"`
+	Tier            int      `json:"tier"`
+	PromotionSpots  int      `json:"promotion_spots"`
+	RelegationSpots int      `json:"relegation_spots"`
+	PlayoffSpots    int      `json:"playoff_spots"`
+	Teams           []string `json:"teams"`
+}
+
+type DivisionMatch struct {
+	ID         int    `json:"id"`
+	Season     int    `json:"season"`
+	DivisionID int    `json:"division_id"`
+	Week       int    `json:"week"`
+	HomeTeam   string `json:"home_team"`
+	AwayTeam   string `json:"away_team"`
+	HomeGoals  int    `json:"home_goals"`
+	AwayGoals  int    `json:"away_goals"`
+}
+
+type DivisionMovement struct {
+	ID             int    `json:"id"`
+	Season         int    `json:"season"`
+	TeamName       string `json:"team_name"`
+	FromDivisionID int    `json:"from_division_id"`
+	FromDivision   string `json:"from_division"`
+	ToDivisionID   int    `json:"to_division_id"`
+	ToDivision     string `json:"to_division"`
+	Movement       string `json:"movement"`
+	Reason         string `json:"reason"`
+}
@@ -603,9 +603,29 @@ def predict_league_outcomes():
         }
     }
 
+def predict_fixtures(request):
+    """Win probabilities for given fixtures from the given historical matches only, used for backtesting"""
+    random.seed(0)  # Injuries and the chosen score are random; keep backtests repeatable
+    predictor = AdvancedPredictor()
+    predictor.load_historical_data(request.get("historical_matches") or [])
+
+    predictions = []
+    for fixture in request.get("fixtures") or []:
+        home_team = fixture["home_team"]
+        away_team = fixture["away_team"]
+        if home_team not in predictor.teams_data or away_team not in predictor.teams_data:
+            predictions.append(None)
+            continue
+        prediction = predictor.predict_match_advanced(home_team, away_team, fixture.get("week", 1))
+        predictions.append(prediction["win_probabilities"])
+    return {"predictions": predictions}
+
 if __name__ == "__main__":
     try:
-        result = predict_league_outcomes()
+        if len(sys.argv) > 1 and sys.argv[1] == "--fixtures":
+            result = predict_fixtures(json.loads(sys.stdin.read()))
+        else:
+            result = predict_league_outcomes()
         print(json.dumps(result, indent=2))
     except Exception as e:
         error_response = {"error": str(e), "timestamp": datetime.now().isoformat()}
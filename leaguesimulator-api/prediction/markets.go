@@ -0,0 +1,232 @@
+package prediction
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// maxMatrixGoals is the highest score per side kept in a Poisson score matrix
+	maxMatrixGoals = 10
+	// correctScoreCount is how many correct scores the markets list
+	correctScoreCount = 10
+)
+
+// ScoreMatrix holds the probability of every score: ScoreMatrix[h][a] is the
+// chance that the home side scores h and the away side a
+type ScoreMatrix [][]float64
+
+// Price is the chance of an outcome with its fair decimal odds (1/probability).
+// Odds are left out for outcomes that cannot happen.
+type Price struct {
+	Probability float64 `json:"probability"`
+	Odds        float64 `json:"odds,omitempty"`
+}
+
+// ResultMarket is the 1X2 market
+type ResultMarket struct {
+	Home Price `json:"home"`
+	Draw Price `json:"draw"`
+	Away Price `json:"away"`
+}
+
+// TotalGoalsLine is one over/under line
+type TotalGoalsLine struct {
+	Line  float64 `json:"line"`
+	Over  Price   `json:"over"`
+	Under Price   `json:"under"`
+}
+
+// BothTeamsToScoreMarket is the both teams to score market
+type BothTeamsToScoreMarket struct {
+	Yes Price `json:"yes"`
+	No  Price `json:"no"`
+}
+
+// HandicapLine is one Asian handicap line, given from the home side: at -1
+// the home side starts a goal down. On whole lines the stake is returned
+// when the adjusted score is level (Push); quarter lines split the stake over
+// the two neighbouring lines. Fair odds make both bets break even.
+type HandicapLine struct {
+	Line    float64 `json:"line"`
+	Home    Price   `json:"home"`
+	Away    Price   `json:"away"`
+	HomeWin float64 `json:"home_win"`
+	Push    float64 `json:"push"`
+	AwayWin float64 `json:"away_win"`
+	Quarter bool    `json:"quarter_line"`
+}
+
+// CorrectScore is the chance of one exact score
+type CorrectScore struct {
+	HomeGoals int `json:"home_goals"`
+	AwayGoals int `json:"away_goals"`
+	Price
+}
+
+// Markets is the full set of fair prices derived from a score matrix
+type Markets struct {
+	ExpectedGoals    ExpectedGoals          `json:"expected_goals"`
+	Result           ResultMarket           `json:"result_1x2"`
+	TotalGoals       []TotalGoalsLine       `json:"over_under"`
+	BothTeamsToScore BothTeamsToScoreMarket `json:"both_teams_to_score"`
+	AsianHandicap    []HandicapLine         `json:"asian_handicap"`
+	CorrectScores    []CorrectScore         `json:"correct_scores"`
+}
+
+// PoissonMatrix builds the score matrix of two sides scoring independently
+// at the given rates, cut off at maxMatrixGoals and scaled to sum to one
+func PoissonMatrix(homeRate, awayRate float64) ScoreMatrix {
+	matrix := make(ScoreMatrix, maxMatrixGoals+1)
+	for h := range matrix {
+		matrix[h] = make([]float64, maxMatrixGoals+1)
+		for a := range matrix[h] {
+			matrix[h][a] = poissonPMF(h, homeRate) * poissonPMF(a, awayRate)
+		}
+	}
+	return matrix.normalize()
+}
+
+// NewScoreMatrix wraps score probabilities from another model, scaled to sum to one
+func NewScoreMatrix(probabilities [][]float64) ScoreMatrix {
+	matrix := make(ScoreMatrix, len(probabilities))
+	for h, row := range probabilities {
+		matrix[h] = append([]float64{}, row...)
+	}
+	return matrix.normalize()
+}
+
+func (m ScoreMatrix) normalize() ScoreMatrix {
+	total := 0.0
+	for _, row := range m {
+		for _, p := range row {
+			total += p
+		}
+	}
+	if total == 0 {
+		return m
+	}
+	for _, row := range m {
+		for a := range row {
+			row[a] /= total
+		}
+	}
+	return m
+}
+
+// probability adds up the scores that match a condition
+func (m ScoreMatrix) probability(match func(home, away int) bool) float64 {
+	total := 0.0
+	for h, row := range m {
+		for a, p := range row {
+			if match(h, a) {
+				total += p
+			}
+		}
+	}
+	return total
+}
+
+// BuildMarkets prices 1X2, over/under 0.5-4.5, both teams to score, Asian
+// handicap lines from -2.5 to +2.5 and the ten likeliest correct scores
+func BuildMarkets(matrix ScoreMatrix) Markets {
+	var markets Markets
+	for h, row := range matrix {
+		for a, p := range row {
+			markets.ExpectedGoals.Home += float64(h) * p
+			markets.ExpectedGoals.Away += float64(a) * p
+		}
+	}
+	markets.ExpectedGoals.Home = Round(markets.ExpectedGoals.Home, 3)
+	markets.ExpectedGoals.Away = Round(markets.ExpectedGoals.Away, 3)
+
+	markets.Result = ResultMarket{
+		Home: price(matrix.probability(func(h, a int) bool { return h > a })),
+		Draw: price(matrix.probability(func(h, a int) bool { return h == a })),
+		Away: price(matrix.probability(func(h, a int) bool { return h < a })),
+	}
+
+	for line := 0.5; line <= 4.5; line++ {
+		over := matrix.probability(func(h, a int) bool { return float64(h+a) > line })
+		markets.TotalGoals = append(markets.TotalGoals, TotalGoalsLine{Line: line, Over: price(over), Under: price(1 - over)})
+	}
+
+	yes := matrix.probability(func(h, a int) bool { return h > 0 && a > 0 })
+	markets.BothTeamsToScore = BothTeamsToScoreMarket{Yes: price(yes), No: price(1 - yes)}
+
+	for quarters := -10; quarters <= 10; quarters++ {
+		markets.AsianHandicap = append(markets.AsianHandicap, handicapLine(matrix, float64(quarters)/4))
+	}
+
+	for h, row := range matrix {
+		for a, p := range row {
+			markets.CorrectScores = append(markets.CorrectScores, CorrectScore{HomeGoals: h, AwayGoals: a, Price: price(p)})
+		}
+	}
+	sort.SliceStable(markets.CorrectScores, func(i, j int) bool {
+		return markets.CorrectScores[i].Probability > markets.CorrectScores[j].Probability
+	})
+	if len(markets.CorrectScores) > correctScoreCount {
+		markets.CorrectScores = markets.CorrectScores[:correctScoreCount]
+	}
+	return markets
+}
+
+// handicapLine prices one Asian handicap line. A quarter line is half a bet
+// on each neighbouring half or whole line, so a side breaks even at odds of
+// 1 + (stake lost)/(stake won) summed over both halves.
+func handicapLine(matrix ScoreMatrix, line float64) HandicapLine {
+	halves := []float64{line, line}
+	quarter := math.Mod(math.Abs(line)*4, 2) == 1
+	if quarter {
+		halves = []float64{line - 0.25, line + 0.25}
+	}
+
+	result := HandicapLine{Line: line, Quarter: quarter}
+	for _, half := range halves {
+		result.HomeWin += matrix.probability(func(h, a int) bool { return float64(h-a)+half > 0 }) / 2
+		result.Push += matrix.probability(func(h, a int) bool { return float64(h-a)+half == 0 }) / 2
+		result.AwayWin += matrix.probability(func(h, a int) bool { return float64(h-a)+half < 0 }) / 2
+	}
+	result.Home = breakEven(result.HomeWin, result.AwayWin)
+	result.Away = breakEven(result.AwayWin, result.HomeWin)
+	result.HomeWin = Round(result.HomeWin, 4)
+	result.Push = Round(result.Push, 4)
+	result.AwayWin = Round(result.AwayWin, 4)
+	return result
+}
+
+// breakEven prices a bet that wins with one chance and loses with another,
+// with any remaining chance returning the stake. Probability is the chance
+// of winning given that the stake is not returned.
+func breakEven(win, lose float64) Price {
+	if win+lose == 0 {
+		return Price{}
+	}
+	return price(win / (win + lose))
+}
+
+func price(probability float64) Price {
+	result := Price{Probability: Round(probability, 4)}
+	if probability > 0 {
+		result.Odds = Round(1/probability, 2)
+	}
+	return result
+}
+
+func poissonPMF(goals int, rate float64) float64 {
+	if rate <= 0 {
+		if goals == 0 {
+			return 1
+		}
+		return 0
+	}
+	logFactorial, _ := math.Lgamma(float64(goals) + 1)
+	return math.Exp(float64(goals)*math.Log(rate) - rate - logFactorial)
+}
+
+// Round rounds value to the given number of decimal places
+func Round(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
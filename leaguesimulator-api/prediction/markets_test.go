@@ -0,0 +1,85 @@
+package prediction
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHandicapLine(t *testing.T) {
+	// Home wins by one 30% of the time, draws 60% and loses by one 10%
+	matrix := ScoreMatrix{
+		{0.2, 0.1},
+		{0.3, 0.4},
+	}
+
+	tests := []struct {
+		name    string
+		line    float64
+		homeWin float64
+		push    float64
+		awayWin float64
+		quarter bool
+	}{
+		{name: "level whole line", line: 0, homeWin: 0.3, push: 0.6, awayWin: 0.1},
+		{name: "whole line against the home side", line: -1, homeWin: 0, push: 0.3, awayWin: 0.7},
+		{name: "half line against the home side", line: -0.5, homeWin: 0.3, push: 0, awayWin: 0.7},
+		{name: "half line for the home side", line: 0.5, homeWin: 0.9, push: 0, awayWin: 0.1},
+		{name: "quarter line between 0 and -0.5", line: -0.25, homeWin: 0.3, push: 0.3, awayWin: 0.4, quarter: true},
+		{name: "quarter line between 0 and +0.5", line: 0.25, homeWin: 0.6, push: 0.3, awayWin: 0.1, quarter: true},
+		{name: "quarter line between -0.5 and -1", line: -0.75, homeWin: 0.15, push: 0.15, awayWin: 0.7, quarter: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := handicapLine(matrix, test.line)
+			if got.Quarter != test.quarter {
+				t.Errorf("quarter = %v, want %v", got.Quarter, test.quarter)
+			}
+			if !near(got.HomeWin, test.homeWin) || !near(got.Push, test.push) || !near(got.AwayWin, test.awayWin) {
+				t.Errorf("home/push/away = %v/%v/%v, want %v/%v/%v",
+					got.HomeWin, got.Push, got.AwayWin, test.homeWin, test.push, test.awayWin)
+			}
+			if total := got.HomeWin + got.Push + got.AwayWin; !near(total, 1) {
+				t.Errorf("outcomes sum to %v, want 1", total)
+			}
+			if test.homeWin+test.awayWin > 0 {
+				want := Round(test.homeWin/(test.homeWin+test.awayWin), 4)
+				if got.Home.Probability != want {
+					t.Errorf("home break-even probability = %v, want %v", got.Home.Probability, want)
+				}
+			}
+		})
+	}
+}
+
+func TestHandicapLineQuarterIsAverageOfNeighbours(t *testing.T) {
+	matrix := PoissonMatrix(1.6, 1.1)
+
+	for quarters := -9; quarters <= 9; quarters += 2 {
+		line := float64(quarters) / 4
+		got := handicapLine(matrix, line)
+		lower := handicapLine(matrix, line-0.25)
+		upper := handicapLine(matrix, line+0.25)
+
+		if !got.Quarter || lower.Quarter || upper.Quarter {
+			t.Fatalf("line %v: quarter flags %v/%v/%v", line, lower.Quarter, got.Quarter, upper.Quarter)
+		}
+		if want := (lower.HomeWin + upper.HomeWin) / 2; !near(got.HomeWin, want) {
+			t.Errorf("line %v: home win %v, want %v", line, got.HomeWin, want)
+		}
+		if want := (lower.Push + upper.Push) / 2; !near(got.Push, want) {
+			t.Errorf("line %v: push %v, want %v", line, got.Push, want)
+		}
+		if want := (lower.AwayWin + upper.AwayWin) / 2; !near(got.AwayWin, want) {
+			t.Errorf("line %v: away win %v, want %v", line, got.AwayWin, want)
+		}
+		if total := got.HomeWin + got.Push + got.AwayWin; !near(total, 1) {
+			t.Errorf("line %v: outcomes sum to %v, want 1", line, total)
+		}
+	}
+}
+
+// near allows for the four decimal places the market prices are rounded to
+func near(got, want float64) bool {
+	return math.Abs(got-want) < 2e-4
+}
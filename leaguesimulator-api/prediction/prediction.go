@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"leaguesimulator/db"
+	"leaguesimulator/models"
 	"log"
 	"os/exec"
 	"runtime"
@@ -114,7 +115,23 @@ func (aps *AdvancedPredictionService) RunAdvancedPrediction() (*ComprehensivePre
 		log.Printf("Warning: could not marshal historical data: %v", err)
 	}
 
-	cmd := exec.Command(aps.pythonExecutable, aps.scriptPath)
+	out, err := aps.runScript(historicalData)
+	if err != nil {
+		return nil, err
+	}
+
+	var prediction ComprehensivePrediction
+	err = json.Unmarshal(out, &prediction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prediction results: %v", err)
+	}
+
+	return &prediction, nil
+}
+
+// runScript runs the prediction script with input on stdin and returns its output
+func (aps *AdvancedPredictionService) runScript(input []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command(aps.pythonExecutable, append([]string{aps.scriptPath}, args...)...)
 
 	// Create stdin pipe
 	stdin, err := cmd.StdinPipe()
@@ -122,10 +139,10 @@ func (aps *AdvancedPredictionService) RunAdvancedPrediction() (*ComprehensivePre
 		return nil, fmt.Errorf("failed to create stdin pipe: %v", err)
 	}
 
-	// Write historical data to stdin
+	// Write the input to stdin
 	go func() {
 		defer stdin.Close()
-		stdin.Write(historicalData)
+		stdin.Write(input)
 	}()
 
 	out, err := cmd.Output()
@@ -135,14 +152,14 @@ func (aps *AdvancedPredictionService) RunAdvancedPrediction() (*ComprehensivePre
 		if aps.pythonExecutable == "python" {
 			altPython = "python3"
 		}
-		cmd = exec.Command(altPython, aps.scriptPath)
+		cmd = exec.Command(altPython, append([]string{aps.scriptPath}, args...)...)
 
-		// Try again with historical data
+		// Try again with the same input
 		stdin, err = cmd.StdinPipe()
 		if err == nil {
 			go func() {
 				defer stdin.Close()
-				stdin.Write(historicalData)
+				stdin.Write(input)
 			}()
 			out, err = cmd.Output()
 		}
@@ -151,14 +168,47 @@ func (aps *AdvancedPredictionService) RunAdvancedPrediction() (*ComprehensivePre
 			return nil, fmt.Errorf("failed to execute prediction script: %v", err)
 		}
 	}
+	return out, nil
+}
 
-	var prediction ComprehensivePrediction
-	err = json.Unmarshal(out, &prediction)
+// Fixture is a match for PredictFixtures to predict
+type Fixture struct {
+	HomeTeam string `json:"home_team"`
+	AwayTeam string `json:"away_team"`
+	Week     int    `json:"week"`
+}
+
+// PredictFixtures runs the prediction model with only the given matches as
+// its history and returns its win probabilities, in percent, for each
+// fixture. Fixtures with a team the model does not know get nil.
+func (aps *AdvancedPredictionService) PredictFixtures(history []models.HistoricalMatch, fixtures []Fixture) ([]*WinProbabilities, error) {
+	input, err := json.Marshal(struct {
+		HistoricalMatches []models.HistoricalMatch `json:"historical_matches"`
+		Fixtures          []Fixture                `json:"fixtures"`
+	}{history, fixtures})
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse prediction results: %v", err)
+		return nil, err
 	}
 
-	return &prediction, nil
+	out, err := aps.runScript(input, "--fixtures")
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Predictions []*WinProbabilities `json:"predictions"`
+		Error       string              `json:"error"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse prediction results: %v", err)
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("prediction script failed: %s", result.Error)
+	}
+	if len(result.Predictions) != len(fixtures) {
+		return nil, fmt.Errorf("prediction script returned %d predictions for %d fixtures", len(result.Predictions), len(fixtures))
+	}
+	return result.Predictions, nil
 }
 
 // GetMatchPrediction gets prediction for a specific match
@@ -0,0 +1,372 @@
+package ratings
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	// DefaultHalfLife is the age, in weeks, at which a match counts half
+	DefaultHalfLife = 38.0
+	// DefaultRegularization keeps the ratings of teams with few matches near average
+	DefaultRegularization = 0.01
+
+	maxIterations = 200
+	tolerance     = 1e-8
+	maxStep       = 1.0
+)
+
+// ErrNoData is returned when there are no matches to fit
+var ErrNoData = errors.New("not enough matches to fit ratings")
+
+// Match is one played match. Age is how long ago it was played, in the same
+// unit as the half-life; the most recent match has age 0.
+type Match struct {
+	Home      string
+	Away      string
+	HomeGoals int
+	AwayGoals int
+	Age       float64
+}
+
+// Options tune the fit. A zero HalfLife uses DefaultHalfLife and a negative
+// one turns time decay off. A zero Regularization uses DefaultRegularization.
+type Options struct {
+	HalfLife       float64 `json:"half_life"`
+	Regularization float64 `json:"regularization"`
+}
+
+// TeamRating is a team's fitted attack and defence on the log scale, centred
+// on zero for an average team, with its actual and fitted goals. The rates
+// are goals per match against an average side at a neutral ground.
+type TeamRating struct {
+	Team                 string  `json:"team"`
+	Attack               float64 `json:"attack"`
+	Defence              float64 `json:"defence"`
+	Matches              int     `json:"matches"`
+	GoalsFor             int     `json:"goals_for"`
+	GoalsAgainst         int     `json:"goals_against"`
+	ExpectedGoalsFor     float64 `json:"expected_goals_for"`
+	ExpectedGoalsAgainst float64 `json:"expected_goals_against"`
+	ScoringRate          float64 `json:"scoring_rate"`
+	ConcedingRate        float64 `json:"conceding_rate"`
+}
+
+// Goodness describes how well the fitted model explains the matches, with
+// every match counted once regardless of its weight
+type Goodness struct {
+	LogLikelihood     float64 `json:"log_likelihood"`
+	NullLogLikelihood float64 `json:"null_log_likelihood"`
+	PseudoR2          float64 `json:"pseudo_r2"`
+	Deviance          float64 `json:"deviance"`
+	AIC               float64 `json:"aic"`
+	RMSEGoals         float64 `json:"rmse_goals"`
+	ActualGoals       int     `json:"actual_goals"`
+	ExpectedGoals     float64 `json:"expected_goals"`
+	ResultAccuracy    float64 `json:"result_accuracy"`
+}
+
+// Fit is a fitted Poisson model: the home side scores at
+// exp(Intercept + HomeAdvantage + attack(home) - defence(away)) goals per
+// match and the away side at exp(Intercept + attack(away) - defence(home))
+type Fit struct {
+	Intercept        float64      `json:"intercept"`
+	HomeAdvantage    float64      `json:"home_advantage"`
+	Teams            []TeamRating `json:"teams"`
+	Goodness         Goodness     `json:"goodness_of_fit"`
+	Matches          int          `json:"matches"`
+	EffectiveMatches float64      `json:"effective_matches"`
+	Iterations       int          `json:"iterations"`
+	Converged        bool         `json:"converged"`
+	Options          Options      `json:"options"`
+}
+
+// ExpectedGoals returns the fitted goal rates of a match, and false when
+// either team was not part of the fit
+func (f *Fit) ExpectedGoals(home, away string) (float64, float64, bool) {
+	homeRating, homeOK := f.team(home)
+	awayRating, awayOK := f.team(away)
+	if !homeOK || !awayOK {
+		return 0, 0, false
+	}
+	return math.Exp(f.Intercept + f.HomeAdvantage + homeRating.Attack - awayRating.Defence),
+		math.Exp(f.Intercept + awayRating.Attack - homeRating.Defence), true
+}
+
+func (f *Fit) team(name string) (TeamRating, bool) {
+	for _, rating := range f.Teams {
+		if rating.Team == name {
+			return rating, true
+		}
+	}
+	return TeamRating{}, false
+}
+
+// FitPoisson estimates attack, defence and home advantage by weighted
+// maximum likelihood, where a match of age a weighs 0.5^(a/half-life). The
+// likelihood is maximised by block coordinate Newton steps: the attack
+// ratings only interact through the intercept, so all of them can be updated
+// at once, then all defence ratings, then the intercept and home advantage.
+func FitPoisson(matches []Match, options Options) (*Fit, error) {
+	if options.HalfLife == 0 {
+		options.HalfLife = DefaultHalfLife
+	}
+	if options.Regularization == 0 {
+		options.Regularization = DefaultRegularization
+	}
+	if len(matches) == 0 {
+		return nil, ErrNoData
+	}
+
+	index := make(map[string]int)
+	var names []string
+	for _, match := range matches {
+		for _, team := range []string{match.Home, match.Away} {
+			if _, ok := index[team]; !ok {
+				index[team] = len(names)
+				names = append(names, team)
+			}
+		}
+	}
+	if len(names) < 2 {
+		return nil, fmt.Errorf("%w: at least two teams are needed", ErrNoData)
+	}
+
+	weights := make([]float64, len(matches))
+	totalWeight := 0.0
+	homeGoals, awayGoals := 0.0, 0.0
+	for i, match := range matches {
+		weights[i] = 1
+		if options.HalfLife > 0 {
+			weights[i] = math.Pow(0.5, match.Age/options.HalfLife)
+		}
+		totalWeight += weights[i]
+		homeGoals += weights[i] * float64(match.HomeGoals)
+		awayGoals += weights[i] * float64(match.AwayGoals)
+	}
+	if homeGoals == 0 || awayGoals == 0 {
+		return nil, fmt.Errorf("%w: both home and away sides need to have scored", ErrNoData)
+	}
+
+	teams := len(names)
+	attack := make([]float64, teams)
+	defence := make([]float64, teams)
+	intercept := math.Log(awayGoals / totalWeight)
+	home := math.Log(homeGoals / awayGoals)
+	ridge := options.Regularization
+
+	rates := func(match Match) (float64, float64) {
+		h, a := index[match.Home], index[match.Away]
+		return math.Exp(intercept + home + attack[h] - defence[a]),
+			math.Exp(intercept + attack[a] - defence[h])
+	}
+
+	fit := &Fit{Matches: len(matches), EffectiveMatches: totalWeight, Options: options}
+	for fit.Iterations < maxIterations {
+		fit.Iterations++
+		change := 0.0
+
+		// Attack: the gradient is weighted goals scored minus expected
+		gradient := make([]float64, teams)
+		curvature := make([]float64, teams)
+		for i, match := range matches {
+			homeRate, awayRate := rates(match)
+			h, a := index[match.Home], index[match.Away]
+			gradient[h] += weights[i] * (float64(match.HomeGoals) - homeRate)
+			curvature[h] += weights[i] * homeRate
+			gradient[a] += weights[i] * (float64(match.AwayGoals) - awayRate)
+			curvature[a] += weights[i] * awayRate
+		}
+		for t := range attack {
+			step := newtonStep(gradient[t]-ridge*attack[t], curvature[t]+ridge)
+			attack[t] += step
+			change = math.Max(change, math.Abs(step))
+		}
+
+		// Defence: conceding fewer goals than expected raises the rating
+		gradient = make([]float64, teams)
+		curvature = make([]float64, teams)
+		for i, match := range matches {
+			homeRate, awayRate := rates(match)
+			h, a := index[match.Home], index[match.Away]
+			gradient[a] -= weights[i] * (float64(match.HomeGoals) - homeRate)
+			curvature[a] += weights[i] * homeRate
+			gradient[h] -= weights[i] * (float64(match.AwayGoals) - awayRate)
+			curvature[h] += weights[i] * awayRate
+		}
+		for t := range defence {
+			step := newtonStep(gradient[t]-ridge*defence[t], curvature[t]+ridge)
+			defence[t] += step
+			change = math.Max(change, math.Abs(step))
+		}
+
+		// Centre both sets of ratings on zero and move the level into the intercept
+		meanAttack, meanDefence := mean(attack), mean(defence)
+		for t := range attack {
+			attack[t] -= meanAttack
+			defence[t] -= meanDefence
+		}
+		intercept += meanAttack - meanDefence
+
+		// Intercept and home advantage
+		var interceptGradient, interceptCurvature, homeGradient, homeCurvature float64
+		for i, match := range matches {
+			homeRate, awayRate := rates(match)
+			interceptGradient += weights[i] * (float64(match.HomeGoals) - homeRate + float64(match.AwayGoals) - awayRate)
+			interceptCurvature += weights[i] * (homeRate + awayRate)
+			homeGradient += weights[i] * (float64(match.HomeGoals) - homeRate)
+			homeCurvature += weights[i] * homeRate
+		}
+		step := newtonStep(interceptGradient, interceptCurvature)
+		intercept += step
+		change = math.Max(change, math.Abs(step))
+		step = newtonStep(homeGradient, homeCurvature)
+		home += step
+		change = math.Max(change, math.Abs(step))
+
+		if change < tolerance {
+			fit.Converged = true
+			break
+		}
+	}
+
+	fit.Intercept = intercept
+	fit.HomeAdvantage = home
+	fit.Teams = make([]TeamRating, teams)
+	for t, name := range names {
+		fit.Teams[t] = TeamRating{
+			Team:          name,
+			Attack:        attack[t],
+			Defence:       defence[t],
+			ScoringRate:   math.Exp(intercept + attack[t]),
+			ConcedingRate: math.Exp(intercept - defence[t]),
+		}
+	}
+	fit.Goodness = goodness(matches, fit, index, rates, teams)
+	sort.SliceStable(fit.Teams, func(i, j int) bool {
+		return fit.Teams[i].Attack+fit.Teams[i].Defence > fit.Teams[j].Attack+fit.Teams[j].Defence
+	})
+	return fit, nil
+}
+
+// goodness compares the fitted rates with the actual scores and with a null
+// model that only knows the average home and away scoring rates
+func goodness(matches []Match, fit *Fit, index map[string]int, rates func(Match) (float64, float64), teams int) Goodness {
+	var result Goodness
+	var homeTotal, awayTotal, squares float64
+	correct := 0
+	for _, match := range matches {
+		homeTotal += float64(match.HomeGoals)
+		awayTotal += float64(match.AwayGoals)
+	}
+	n := float64(len(matches))
+	nullHome, nullAway := homeTotal/n, awayTotal/n
+
+	for _, match := range matches {
+		homeRate, awayRate := rates(match)
+		h, a := index[match.Home], index[match.Away]
+		for _, side := range []struct {
+			goals int
+			rate  float64
+			null  float64
+		}{
+			{match.HomeGoals, homeRate, nullHome},
+			{match.AwayGoals, awayRate, nullAway},
+		} {
+			result.LogLikelihood += poissonLogPMF(side.goals, side.rate)
+			result.NullLogLikelihood += poissonLogPMF(side.goals, side.null)
+			y := float64(side.goals)
+			if y > 0 {
+				result.Deviance += 2 * (y*math.Log(y/side.rate) - (y - side.rate))
+			} else {
+				result.Deviance += 2 * side.rate
+			}
+			squares += (y - side.rate) * (y - side.rate)
+			result.ExpectedGoals += side.rate
+		}
+		result.ActualGoals += match.HomeGoals + match.AwayGoals
+
+		fit.Teams[h].Matches++
+		fit.Teams[a].Matches++
+		fit.Teams[h].GoalsFor += match.HomeGoals
+		fit.Teams[h].GoalsAgainst += match.AwayGoals
+		fit.Teams[a].GoalsFor += match.AwayGoals
+		fit.Teams[a].GoalsAgainst += match.HomeGoals
+		fit.Teams[h].ExpectedGoalsFor += homeRate
+		fit.Teams[h].ExpectedGoalsAgainst += awayRate
+		fit.Teams[a].ExpectedGoalsFor += awayRate
+		fit.Teams[a].ExpectedGoalsAgainst += homeRate
+
+		homeWin, draw, awayWin := ResultProbabilities(homeRate, awayRate)
+		predicted := sign(homeWin - awayWin)
+		if draw > homeWin && draw > awayWin {
+			predicted = 0
+		}
+		if predicted == sign(float64(match.HomeGoals-match.AwayGoals)) {
+			correct++
+		}
+	}
+
+	parameters := float64(2*(teams-1) + 2)
+	result.AIC = 2*parameters - 2*result.LogLikelihood
+	if result.NullLogLikelihood != 0 {
+		result.PseudoR2 = 1 - result.LogLikelihood/result.NullLogLikelihood
+	}
+	result.RMSEGoals = math.Sqrt(squares / (2 * n))
+	result.ResultAccuracy = float64(correct) / n
+	return result
+}
+
+// ResultProbabilities returns the chances of a home win, a draw and an away
+// win when both sides score independently at the given Poisson rates
+func ResultProbabilities(homeRate, awayRate float64) (homeWin, draw, awayWin float64) {
+	const maxGoals = 15
+	for h := 0; h <= maxGoals; h++ {
+		for a := 0; a <= maxGoals; a++ {
+			p := math.Exp(poissonLogPMF(h, homeRate) + poissonLogPMF(a, awayRate))
+			switch {
+			case h > a:
+				homeWin += p
+			case h < a:
+				awayWin += p
+			default:
+				draw += p
+			}
+		}
+	}
+	return homeWin, draw, awayWin
+}
+
+func poissonLogPMF(goals int, rate float64) float64 {
+	logFactorial, _ := math.Lgamma(float64(goals) + 1)
+	return float64(goals)*math.Log(rate) - rate - logFactorial
+}
+
+// newtonStep is one Newton step for a concave log-likelihood, capped so that
+// early iterations cannot overshoot
+func newtonStep(gradient, curvature float64) float64 {
+	if curvature <= 0 {
+		return 0
+	}
+	return math.Max(-maxStep, math.Min(maxStep, gradient/curvature))
+}
+
+func mean(values []float64) float64 {
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+	return total / float64(len(values))
+}
+
+func sign(value float64) int {
+	switch {
+	case value > 0:
+		return 1
+	case value < 0:
+		return -1
+	}
+	return 0
+}
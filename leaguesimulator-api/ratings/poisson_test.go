@@ -0,0 +1,106 @@
+package ratings
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFitPoissonRecoversKnownParameters(t *testing.T) {
+	const (
+		intercept     = 0.2
+		homeAdvantage = 0.3
+	)
+	teams := []string{"Lions", "Tigers", "Bears", "Wolves"}
+	attack := map[string]float64{"Lions": 0.4, "Tigers": 0.1, "Bears": -0.1, "Wolves": -0.4}
+	defence := map[string]float64{"Lions": 0.2, "Tigers": -0.3, "Bears": 0.3, "Wolves": -0.2}
+
+	tests := []struct {
+		name     string
+		meetings int
+		options  Options
+	}{
+		{name: "equal weights", meetings: 300, options: Options{HalfLife: -1, Regularization: 1e-9}},
+		{name: "time decay", meetings: 600, options: Options{HalfLife: 200, Regularization: 1e-9}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			random := rand.New(rand.NewSource(1))
+			var matches []Match
+			for round := 0; round < test.meetings; round++ {
+				for _, home := range teams {
+					for _, away := range teams {
+						if home == away {
+							continue
+						}
+						matches = append(matches, Match{
+							Home:      home,
+							Away:      away,
+							HomeGoals: poissonSample(random, math.Exp(intercept+homeAdvantage+attack[home]-defence[away])),
+							AwayGoals: poissonSample(random, math.Exp(intercept+attack[away]-defence[home])),
+							Age:       float64(test.meetings - 1 - round),
+						})
+					}
+				}
+			}
+
+			fit, err := FitPoisson(matches, test.options)
+			if err != nil {
+				t.Fatalf("FitPoisson: %v", err)
+			}
+			if !fit.Converged {
+				t.Fatalf("fit did not converge in %d iterations", fit.Iterations)
+			}
+
+			const tolerance = 0.08
+			if math.Abs(fit.Intercept-intercept) > tolerance {
+				t.Errorf("intercept = %.3f, want %.3f", fit.Intercept, intercept)
+			}
+			if math.Abs(fit.HomeAdvantage-homeAdvantage) > tolerance {
+				t.Errorf("home advantage = %.3f, want %.3f", fit.HomeAdvantage, homeAdvantage)
+			}
+			for _, rating := range fit.Teams {
+				if math.Abs(rating.Attack-attack[rating.Team]) > tolerance {
+					t.Errorf("%s attack = %.3f, want %.3f", rating.Team, rating.Attack, attack[rating.Team])
+				}
+				if math.Abs(rating.Defence-defence[rating.Team]) > tolerance {
+					t.Errorf("%s defence = %.3f, want %.3f", rating.Team, rating.Defence, defence[rating.Team])
+				}
+			}
+			if fit.Teams[0].Team != "Lions" {
+				t.Errorf("strongest team = %s, want Lions", fit.Teams[0].Team)
+			}
+		})
+	}
+}
+
+func TestFitPoissonNeedsGoals(t *testing.T) {
+	tests := []struct {
+		name    string
+		matches []Match
+	}{
+		{name: "no matches"},
+		{name: "one team", matches: []Match{{Home: "Lions", Away: "Lions", HomeGoals: 1, AwayGoals: 1}}},
+		{name: "no away goals", matches: []Match{{Home: "Lions", Away: "Bears", HomeGoals: 2}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := FitPoisson(test.matches, Options{}); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+// poissonSample draws a Poisson count by multiplying uniforms (Knuth)
+func poissonSample(random *rand.Rand, rate float64) int {
+	limit := math.Exp(-rate)
+	goals, product := 0, random.Float64()
+	for product > limit {
+		goals++
+		product *= random.Float64()
+	}
+	return goals
+}
@@ -0,0 +1,94 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/league"
+)
+
+// registerBacktestRoutes adds the prediction backtesting endpoints
+func registerBacktestRoutes(router *gin.Engine) {
+	// Replay stored seasons with one or more model configurations and save the reports
+	router.POST("/backtests", func(c *gin.Context) {
+		var options league.BacktestOptions
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&options); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+				return
+			}
+		}
+
+		reports, err := manager.RunBacktest(options)
+		if err != nil {
+			respondBacktestError(c, err)
+			return
+		}
+
+		// Best configuration first by log loss
+		ranked := append([]league.BacktestReport{}, reports...)
+		sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Overall.LogLoss < ranked[j].Overall.LogLoss })
+		ranking := []gin.H{}
+		for _, report := range ranked {
+			ranking = append(ranking, gin.H{
+				"id":          report.ID,
+				"name":        report.Config.Name,
+				"accuracy":    report.Overall.Accuracy,
+				"brier_score": report.Overall.BrierScore,
+				"log_loss":    report.Overall.LogLoss,
+			})
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"message": "Backtest finished and reports saved",
+			"ranking": ranking,
+			"reports": reports,
+		})
+	})
+
+	// Saved reports, newest first, for comparing runs
+	router.GET("/backtests", func(c *gin.Context) {
+		reports, err := league.ListBacktestReports()
+		if err != nil {
+			respondBacktestError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"reports": reports,
+			"total":   len(reports),
+		})
+	})
+
+	// One saved report with its per-season scores and calibration table
+	router.GET("/backtests/:id", func(c *gin.Context) {
+		reportID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+			return
+		}
+
+		report, err := league.GetBacktestReport(reportID)
+		if err != nil {
+			respondBacktestError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"report": report})
+	})
+}
+
+func respondBacktestError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, league.ErrInvalidBacktest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, league.ErrBacktestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Backtest failed: " + err.Error(),
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/ical"
+	"leaguesimulator/league"
+	"leaguesimulator/models"
+)
+
+// registerCalendarRoutes adds the venue, fixture calendar and iCalendar export endpoints
+func registerCalendarRoutes(router *gin.Engine) {
+	// List venues with their home teams
+	router.GET("/venues", func(c *gin.Context) {
+		venues, err := league.ListVenues()
+		if err != nil {
+			respondCalendarError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"venues": venues,
+			"total":  len(venues),
+		})
+	})
+
+	// Register a venue
+	router.POST("/venues", func(c *gin.Context) {
+		var venueRequest struct {
+			Name     string `json:"name" binding:"required"`
+			City     string `json:"city"`
+			Capacity int    `json:"capacity"`
+		}
+
+		if err := c.ShouldBindJSON(&venueRequest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		venue, err := league.CreateVenue(venueRequest.Name, venueRequest.City, venueRequest.Capacity)
+		if err != nil {
+			respondCalendarError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"message": "Venue created",
+			"venue":   venue,
+		})
+	})
+
+	// Set the home venue of a team (0 clears it)
+	router.PUT("/team/:name/venue", func(c *gin.Context) {
+		var venueRequest struct {
+			VenueID *int `json:"venue_id" binding:"required"`
+		}
+
+		if err := c.ShouldBindJSON(&venueRequest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		if err := manager.AssignVenue(c.Param("name"), *venueRequest.VenueID); err != nil {
+			respondCalendarError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "Home venue updated",
+			"team":     c.Param("name"),
+			"venue_id": *venueRequest.VenueID,
+		})
+	})
+
+	// Current season start date and matchday pattern
+	router.GET("/calendar/config", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"calendar": manager.Calendar,
+		})
+	})
+
+	// Change the season start date or matchday pattern and reschedule unplayed fixtures
+	router.PUT("/calendar/config", func(c *gin.Context) {
+		var config league.CalendarConfig
+		if err := c.ShouldBindJSON(&config); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		if err := manager.ConfigureCalendar(config); err != nil {
+			respondCalendarError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":           "Calendar updated and fixtures rescheduled",
+			"calendar":          manager.Calendar,
+			"upcoming_fixtures": manager.GetFutureFixtures(),
+		})
+	})
+
+	// Whole league as an iCalendar feed
+	router.GET("/calendar.ics", func(c *gin.Context) {
+		matches := manager.GetMatches()
+		writeCalendar(c, "league-fixtures.ics", ical.BuildCalendar("League Fixtures", matches))
+	})
+
+	// One team's fixtures as an iCalendar feed
+	router.GET("/team/:name/calendar.ics", func(c *gin.Context) {
+		teamName := c.Param("name")
+
+		var teamMatches []models.Match
+		for _, match := range manager.GetMatches() {
+			if match.HomeTeam == teamName || match.AwayTeam == teamName {
+				teamMatches = append(teamMatches, match)
+			}
+		}
+
+		if len(teamMatches) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "No fixtures found for team " + teamName,
+			})
+			return
+		}
+
+		writeCalendar(c, teamName+"-fixtures.ics", ical.BuildCalendar(teamName+" Fixtures", teamMatches))
+	})
+}
+
+func writeCalendar(c *gin.Context, filename string, calendar string) {
+	setAttachment(c, filename)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(calendar))
+}
+
+func respondCalendarError(c *gin.Context, err error) {
+	if errors.Is(err, league.ErrInvalidCalendar) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Calendar operation failed: " + err.Error(),
+	})
+}
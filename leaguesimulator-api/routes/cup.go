@@ -0,0 +1,156 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/league"
+	"leaguesimulator/models"
+)
+
+// registerCupRoutes adds the knockout cup endpoints
+func registerCupRoutes(router *gin.Engine) {
+	// List all cups
+	router.GET("/cups", func(c *gin.Context) {
+		cups, err := league.ListCups()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to load cups: " + err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"cups":  cups,
+			"total": len(cups),
+		})
+	})
+
+	// Create a cup from existing teams
+	router.POST("/cups", func(c *gin.Context) {
+		var cupRequest struct {
+			Name       string   `json:"name" binding:"required"`
+			Teams      []string `json:"teams,omitempty"`
+			Legs       int      `json:"legs,omitempty"`
+			DrawMethod string   `json:"draw_method,omitempty"` // "seeded", "random"
+		}
+
+		if err := c.ShouldBindJSON(&cupRequest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		cup, err := league.CreateCup(cupRequest.Name, cupRequest.Teams, cupRequest.Legs, cupRequest.DrawMethod)
+		if err != nil {
+			respondCupError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"message":      "Cup created",
+			"cup":          cup,
+			"total_rounds": league.CupTotalRounds(cup),
+		})
+	})
+
+	// Get the bracket of a cup
+	router.GET("/cups/:id", func(c *gin.Context) {
+		cup, ok := loadCup(c)
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"cup":          cup,
+			"bracket":      league.CupBracket(cup),
+			"total_rounds": league.CupTotalRounds(cup),
+		})
+	})
+
+	// Draw the next round
+	router.POST("/cups/:id/draw", func(c *gin.Context) {
+		cup, ok := loadCup(c)
+		if !ok {
+			return
+		}
+
+		ties, err := league.DrawCupRound(cup)
+		if err != nil {
+			respondCupError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Round drawn",
+			"round":   cup.CurrentRound,
+			"ties":    ties,
+		})
+	})
+
+	// Play the drawn round
+	router.POST("/cups/:id/play-round", func(c *gin.Context) {
+		cup, ok := loadCup(c)
+		if !ok {
+			return
+		}
+
+		ties, err := league.PlayCupRound(cup)
+		if err != nil {
+			respondCupError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "Round completed",
+			"round":    cup.CurrentRound,
+			"ties":     ties,
+			"status":   cup.Status,
+			"champion": cup.Champion,
+		})
+	})
+}
+
+// loadCup reads the cup named by the :id parameter, writing the error response itself
+func loadCup(c *gin.Context) (*models.Cup, bool) {
+	cupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid cup ID format",
+		})
+		return nil, false
+	}
+
+	cup, err := league.GetCup(cupID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Cup not found",
+		})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to load cup: " + err.Error(),
+		})
+		return nil, false
+	}
+
+	return cup, true
+}
+
+func respondCupError(c *gin.Context, err error) {
+	if errors.Is(err, league.ErrInvalidCup) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Cup operation failed: " + err.Error(),
+	})
+}
@@ -0,0 +1,173 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/league"
+)
+
+// registerDivisionRoutes adds the division pyramid endpoints
+func registerDivisionRoutes(router *gin.Engine) {
+	// Current season divisions, ordered by tier
+	router.GET("/divisions", func(c *gin.Context) {
+		season, divisions, err := league.GetPyramid()
+		if err != nil {
+			respondDivisionError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"season":    season,
+			"divisions": divisions,
+			"total":     len(divisions),
+		})
+	})
+
+	// Add a division to the pyramid
+	router.POST("/divisions", func(c *gin.Context) {
+		var divisionRequest struct {
+			Name            string   `json:"name" binding:"required"`
+			Tier            int      `json:"tier" binding:"required"`
+			Teams           []string `json:"teams" binding:"required"`
+			PromotionSpots  int      `json:"promotion_spots"`
+			RelegationSpots int      `json:"relegation_spots"`
+			PlayoffSpots    int      `json:"playoff_spots"`
+		}
+
+		if err := c.ShouldBindJSON(&divisionRequest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		division, err := league.CreateDivision(
+			divisionRequest.Name,
+			divisionRequest.Tier,
+			divisionRequest.Teams,
+			divisionRequest.PromotionSpots,
+			divisionRequest.RelegationSpots,
+			divisionRequest.PlayoffSpots,
+		)
+		if err != nil {
+			respondDivisionError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"message":  "Division created",
+			"division": division,
+		})
+	})
+
+	// Play the whole current season in every division
+	router.POST("/divisions/play-season", func(c *gin.Context) {
+		tables, err := league.PlayDivisionSeason()
+		if err != nil {
+			respondDivisionError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Division season completed",
+			"tables":  tables,
+		})
+	})
+
+	// Division tables, for the current season unless ?season= is given
+	router.GET("/divisions/tables", func(c *gin.Context) {
+		season, ok := divisionSeasonParam(c)
+		if !ok {
+			return
+		}
+
+		tables, err := league.DivisionTables(season)
+		if err != nil {
+			respondDivisionError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"season": season,
+			"tables": tables,
+		})
+	})
+
+	// Apply promotion and relegation and start the next season
+	router.POST("/divisions/rollover", func(c *gin.Context) {
+		rollover, err := league.RolloverDivisions()
+		if err != nil {
+			respondDivisionError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "Season rolled over",
+			"rollover": rollover,
+		})
+	})
+
+	// Teams that moved between divisions and why, for all seasons unless ?season= is given
+	router.GET("/divisions/movements", func(c *gin.Context) {
+		season := 0
+		if seasonStr := c.Query("season"); seasonStr != "" {
+			var err error
+			season, err = strconv.Atoi(seasonStr)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid season format",
+				})
+				return
+			}
+		}
+
+		movements, err := league.GetDivisionMovements(season)
+		if err != nil {
+			respondDivisionError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"movements": movements,
+			"total":     len(movements),
+		})
+	})
+}
+
+// divisionSeasonParam reads ?season=, defaulting to the current pyramid season
+func divisionSeasonParam(c *gin.Context) (int, bool) {
+	seasonStr := c.Query("season")
+	if seasonStr == "" {
+		season, _, err := league.GetPyramid()
+		if err != nil {
+			respondDivisionError(c, err)
+			return 0, false
+		}
+		return season, true
+	}
+
+	season, err := strconv.Atoi(seasonStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid season format",
+		})
+		return 0, false
+	}
+	return season, true
+}
+
+func respondDivisionError(c *gin.Context, err error) {
+	if errors.Is(err, league.ErrInvalidDivision) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Division operation failed: " + err.Error(),
+	})
+}
@@ -0,0 +1,205 @@
+package routes
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/db"
+	"leaguesimulator/export"
+	"leaguesimulator/league"
+	"leaguesimulator/models"
+)
+
+// registerExportRoutes adds the full-season download
+func registerExportRoutes(router *gin.Engine) {
+	// Standings, results and remaining fixtures as a zip of CSV files
+	router.GET("/export/season", func(c *gin.Context) {
+		format, ok := exportFormat(c)
+		if !ok {
+			return
+		}
+		if format == export.FormatJSON {
+			format = export.FormatCSV
+		}
+
+		var results, fixtures []models.Match
+		for _, match := range manager.GetMatches() {
+			if match.Played {
+				results = append(results, match)
+			} else {
+				fixtures = append(fixtures, match)
+			}
+		}
+		tables := []export.Table{
+			standingsTable(manager.GetStandings()),
+			matchesTable("results", results),
+			fixturesTable(fixtures),
+		}
+
+		var buffer bytes.Buffer
+		if err := export.WriteZip(&buffer, tables, format); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Export failed: " + err.Error()})
+			return
+		}
+		setAttachment(c, fmt.Sprintf("season-%d.zip", db.CurrentSeason))
+		c.Data(http.StatusOK, "application/zip", buffer.Bytes())
+	})
+}
+
+// exportFormat reads ?format= or the Accept header. It answers 400 and
+// returns false for an unknown format.
+func exportFormat(c *gin.Context) (string, bool) {
+	format, err := export.Negotiate(c.Query("format"), c.GetHeader("Accept"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+	return format, true
+}
+
+// writeTable sends a table as a CSV download named after the table
+func writeTable(c *gin.Context, table export.Table, format string) {
+	var buffer bytes.Buffer
+	if err := export.WriteCSV(&buffer, table, format); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Export failed: " + err.Error()})
+		return
+	}
+	setAttachment(c, table.Name+".csv")
+	c.Data(http.StatusOK, export.ContentType(format), buffer.Bytes())
+}
+
+// setAttachment marks the response as a download. Team names end up in file
+// names, so the header is built with quoting and encoding as needed.
+func setAttachment(c *gin.Context, filename string) {
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+}
+
+func standingsTable(standings []league.TeamStanding) export.Table {
+	table := export.Table{
+		Name:   "standings",
+		Header: []string{"position", "team", "played", "won", "drawn", "lost", "goals_for", "goals_against", "goal_diff", "points"},
+	}
+	for i, standing := range standings {
+		table.Rows = append(table.Rows, []string{
+			strconv.Itoa(i + 1),
+			standing.Name,
+			strconv.Itoa(standing.Played),
+			strconv.Itoa(standing.Won),
+			strconv.Itoa(standing.Drawn),
+			strconv.Itoa(standing.Lost),
+			strconv.Itoa(standing.GoalsFor),
+			strconv.Itoa(standing.GoalsAgainst),
+			strconv.Itoa(standing.GoalDiff),
+			strconv.Itoa(standing.Points),
+		})
+	}
+	return table
+}
+
+// matchesTable lists matches with their scores; scores are empty for
+// matches that have not been played
+func matchesTable(name string, matches []models.Match) export.Table {
+	table := export.Table{
+		Name:   name,
+		Header: []string{"id", "week", "home_team", "away_team", "home_goals", "away_goals", "played", "postponed", "kick_off", "venue"},
+	}
+	for _, match := range matches {
+		homeGoals, awayGoals := "", ""
+		if match.Played {
+			homeGoals, awayGoals = strconv.Itoa(match.HomeGoals), strconv.Itoa(match.AwayGoals)
+		}
+		table.Rows = append(table.Rows, []string{
+			strconv.Itoa(match.ID),
+			strconv.Itoa(match.Week),
+			match.HomeTeam,
+			match.AwayTeam,
+			homeGoals,
+			awayGoals,
+			strconv.FormatBool(match.Played),
+			strconv.FormatBool(match.Postponed),
+			export.Time(match.KickOff),
+			match.Venue,
+		})
+	}
+	return table
+}
+
+func fixturesTable(matches []models.Match) export.Table {
+	table := export.Table{
+		Name:   "fixtures",
+		Header: []string{"id", "week", "home_team", "away_team", "kick_off", "venue", "postponed"},
+	}
+	for _, match := range matches {
+		table.Rows = append(table.Rows, []string{
+			strconv.Itoa(match.ID),
+			strconv.Itoa(match.Week),
+			match.HomeTeam,
+			match.AwayTeam,
+			export.Time(match.KickOff),
+			match.Venue,
+			strconv.FormatBool(match.Postponed),
+		})
+	}
+	return table
+}
+
+// upcomingTable lists the remaining fixtures as returned by /fixtures
+func upcomingTable(fixtures []league.MatchView) export.Table {
+	table := export.Table{
+		Name:   "fixtures",
+		Header: []string{"week", "home_team", "away_team", "kick_off", "venue"},
+	}
+	for _, fixture := range fixtures {
+		table.Rows = append(table.Rows, []string{
+			strconv.Itoa(fixture.Week),
+			fixture.Team1,
+			fixture.Team2,
+			export.Time(fixture.KickOff),
+			fixture.Venue,
+		})
+	}
+	return table
+}
+
+// teamMatchesTable lists one team's played matches from its own side with a
+// running points total
+func teamMatchesTable(teamName string, matches []models.Match) export.Table {
+	table := export.Table{
+		Name:   teamName + "-analysis",
+		Header: []string{"week", "venue", "opponent", "goals_for", "goals_against", "result", "points_total"},
+	}
+	points := 0
+	for _, match := range matches {
+		venue, opponent := "home", match.AwayTeam
+		scored, conceded := match.HomeGoals, match.AwayGoals
+		if match.AwayTeam == teamName {
+			venue, opponent = "away", match.HomeTeam
+			scored, conceded = conceded, scored
+		}
+		result := "D"
+		switch {
+		case scored > conceded:
+			result = "W"
+			points += 3
+		case scored < conceded:
+			result = "L"
+		default:
+			points++
+		}
+		table.Rows = append(table.Rows, []string{
+			strconv.Itoa(match.Week),
+			venue,
+			opponent,
+			strconv.Itoa(scored),
+			strconv.Itoa(conceded),
+			result,
+			strconv.Itoa(points),
+		})
+	}
+	return table
+}
@@ -0,0 +1,221 @@
+package routes
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/importer"
+	"leaguesimulator/league"
+)
+
+// registerImportRoutes adds the endpoints that load teams, fixtures and
+// historical results from CSV or JSON files
+func registerImportRoutes(router *gin.Engine) {
+	// Replace the teams with the request body and generate fixtures
+	router.POST("/import/teams", func(c *gin.Context) {
+		format, err := importer.DetectFormat(c.Query("format"), "", c.ContentType())
+		if err != nil {
+			respondImportError(c, err)
+			return
+		}
+
+		options := league.ImportOptions{DryRun: c.Query("dry_run") == "true", RemoveTeams: c.Query("remove_teams") == "true"}
+		summary, err := manager.ImportFiles(c.Request.Body, format, nil, "", options)
+		if err != nil {
+			respondImportError(c, err)
+			return
+		}
+		respondImport(c, summary)
+	})
+
+	// Replace the teams and, optionally, the fixtures with uploaded files
+	router.POST("/import", func(c *gin.Context) {
+		teamsFile, teamsFormat, err := openImportFile(c, "teams")
+		if err != nil {
+			respondImportError(c, err)
+			return
+		}
+		if teamsFile == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "a teams file is required"})
+			return
+		}
+		defer teamsFile.Close()
+
+		fixturesFile, fixturesFormat, err := openImportFile(c, "fixtures")
+		if err != nil {
+			respondImportError(c, err)
+			return
+		}
+		var fixtures io.Reader
+		if fixturesFile != nil {
+			defer fixturesFile.Close()
+			fixtures = fixturesFile
+		}
+
+		options := league.ImportOptions{
+			DryRun:      c.PostForm("dry_run") == "true" || c.Query("dry_run") == "true",
+			RemoveTeams: c.PostForm("remove_teams") == "true" || c.Query("remove_teams") == "true",
+		}
+		summary, err := manager.ImportFiles(teamsFile, teamsFormat, fixtures, fixturesFormat, options)
+		if err != nil {
+			respondImportError(c, err)
+			return
+		}
+		respondImport(c, summary)
+	})
+
+	// Load football-data style results files as historical seasons
+	router.POST("/import/history", func(c *gin.Context) {
+		options := league.HistoryImportOptions{DryRun: c.Query("dry_run") == "true"}
+		if value := c.Query("first_season"); value != "" {
+			season, err := strconv.Atoi(value)
+			if err != nil || season >= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "first_season must be a negative number"})
+				return
+			}
+			options.FirstSeason = season
+		}
+
+		var files []io.Reader
+		if strings.HasPrefix(c.ContentType(), "multipart/") {
+			form, err := c.MultipartForm()
+			if err != nil {
+				respondImportError(c, err)
+				return
+			}
+			for _, header := range form.File["results"] {
+				file, err := header.Open()
+				if err != nil {
+					respondImportError(c, err)
+					return
+				}
+				defer file.Close()
+				files = append(files, file)
+			}
+			if len(files) == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "at least one results file is required"})
+				return
+			}
+		} else {
+			files = append(files, c.Request.Body)
+		}
+
+		summary, err := manager.ImportHistoryFiles(files, options)
+		if err != nil {
+			respondImportError(c, err)
+			return
+		}
+		message := "Historical results imported"
+		if summary.DryRun {
+			message = "Import is valid, nothing was changed"
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message": message,
+			"summary": summary,
+		})
+	})
+
+	// Names that results files use for league teams
+	router.GET("/team-aliases", func(c *gin.Context) {
+		aliases, err := manager.GetTeamAliases()
+		if err != nil {
+			respondImportError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"aliases": aliases})
+	})
+
+	router.PUT("/team-aliases/:alias", func(c *gin.Context) {
+		var request struct {
+			Team string `json:"team" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+			return
+		}
+
+		alias, err := manager.SetTeamAlias(c.Param("alias"), request.Team)
+		if err != nil {
+			respondImportError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Alias saved",
+			"alias":   alias,
+		})
+	})
+
+	router.DELETE("/team-aliases/:alias", func(c *gin.Context) {
+		if err := manager.DeleteTeamAlias(c.Param("alias")); err != nil {
+			respondImportError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Alias deleted"})
+	})
+}
+
+// openImportFile opens an uploaded form file and works out its format from
+// its name. It returns a nil file when the field was not sent.
+func openImportFile(c *gin.Context, field string) (multipart.File, string, error) {
+	header, err := c.FormFile(field)
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	format, err := importer.DetectFormat(c.PostForm(field+"_format"), header.Filename, header.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, "", err
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, "", err
+	}
+	return file, format, nil
+}
+
+func respondImport(c *gin.Context, summary *league.ImportSummary) {
+	message := "League imported"
+	if summary.DryRun {
+		message = "Import is valid, nothing was changed"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":   message,
+		"summary":   summary,
+		"standings": manager.GetStandings(),
+	})
+}
+
+func respondImportError(c *gin.Context, err error) {
+	var validation *importer.ValidationError
+	if errors.As(err, &validation) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  err.Error(),
+			"errors": validation.Rows,
+		})
+		return
+	}
+	if errors.Is(err, league.ErrRemovesHistory) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, league.ErrAliasNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, importer.ErrInvalidImport) || errors.Is(err, league.ErrInvalidAlias) || errors.Is(err, http.ErrNotMultipart) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Import failed: " + err.Error(),
+	})
+}
@@ -0,0 +1,118 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/prediction"
+)
+
+const (
+	marketSourcePrediction = "prediction"
+	marketSourceRatings    = "ratings"
+	marketSourceSimulation = "simulation"
+)
+
+// registerMarketsRoutes adds the betting-style market prices of a match
+func registerMarketsRoutes(router *gin.Engine) {
+	// Fair odds for team1 at home against team2 from a score probability matrix.
+	// ?source=prediction|ratings|simulation picks the model; without it the
+	// prediction model is tried first, then the fitted ratings, then the simulator.
+	router.GET("/predict/:team1/:team2/markets", func(c *gin.Context) {
+		home, away := c.Param("team1"), c.Param("team2")
+		if home == away {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A team cannot play itself"})
+			return
+		}
+		for _, name := range []string{home, away} {
+			if !isLeagueTeam(name) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Team not found: " + name})
+				return
+			}
+		}
+
+		source := c.Query("source")
+		switch source {
+		case "", marketSourcePrediction, marketSourceRatings, marketSourceSimulation:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "source must be prediction, ratings or simulation"})
+			return
+		}
+
+		var matrix prediction.ScoreMatrix
+		var problems []string
+		if source == "" || source == marketSourcePrediction {
+			if homeRate, awayRate, err := predictedGoals(home, away); err == nil {
+				matrix, source = prediction.PoissonMatrix(homeRate, awayRate), marketSourcePrediction
+			} else {
+				problems = append(problems, "prediction model: "+err.Error())
+			}
+		}
+		if matrix == nil && (source == "" || source == marketSourceRatings) {
+			fit, err := manager.GetRatings()
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load ratings: " + err.Error()})
+				return
+			}
+			if fit == nil {
+				problems = append(problems, "ratings: no rating fit has been applied")
+			} else if homeRate, awayRate, ok := fit.ExpectedGoals(home, away); ok {
+				matrix, source = prediction.PoissonMatrix(homeRate, awayRate), marketSourceRatings
+			} else {
+				problems = append(problems, "ratings: the applied fit does not cover both teams")
+			}
+		}
+		if matrix == nil && (source == "" || source == marketSourceSimulation) {
+			probabilities, err := manager.SimulatedScoreProbabilities(home, away)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			matrix, source = prediction.NewScoreMatrix(probabilities), marketSourceSimulation
+		}
+		if matrix == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":    "The " + source + " source cannot price this match",
+				"problems": problems,
+			})
+			return
+		}
+
+		markets := prediction.BuildMarkets(matrix)
+		c.JSON(http.StatusOK, gin.H{
+			"home_team":           home,
+			"away_team":           away,
+			"source":              source,
+			"expected_goals":      markets.ExpectedGoals,
+			"result_1x2":          markets.Result,
+			"over_under":          markets.TotalGoals,
+			"both_teams_to_score": markets.BothTeamsToScore,
+			"asian_handicap":      markets.AsianHandicap,
+			"correct_scores":      markets.CorrectScores,
+			"note":                "Fair odds without bookmaker margin; Asian handicap lines are from the home side",
+		})
+	})
+}
+
+// predictedGoals returns the prediction model's expected goals with the
+// first team at home
+func predictedGoals(home, away string) (float64, float64, error) {
+	match, err := predictionService.GetMatchPrediction(home, away)
+	if err != nil {
+		return 0, 0, err
+	}
+	if match.Team1 == home {
+		return match.ExpectedGoals.Home, match.ExpectedGoals.Away, nil
+	}
+	return match.ExpectedGoals.Away, match.ExpectedGoals.Home, nil
+}
+
+func isLeagueTeam(name string) bool {
+	for _, team := range manager.Teams {
+		if team.Name == name {
+			return true
+		}
+	}
+	return false
+}
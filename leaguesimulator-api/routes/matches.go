@@ -0,0 +1,160 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/league"
+)
+
+// registerMatchRoutes adds the endpoints that address a single match by its database ID
+func registerMatchRoutes(router *gin.Engine) {
+	// Get a match by ID
+	router.GET("/matches/:id", func(c *gin.Context) {
+		matchID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid match ID",
+			})
+			return
+		}
+
+		match, err := manager.GetMatchById(matchID)
+		if err != nil {
+			respondMatchError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, match)
+	})
+
+	// Correct the score of a played match
+	router.PATCH("/matches/:id", func(c *gin.Context) {
+		matchID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid match ID",
+			})
+			return
+		}
+
+		var request struct {
+			HomeGoals *int   `json:"home_goals"`
+			AwayGoals *int   `json:"away_goals"`
+			Reason    string `json:"reason"`
+			Actor     string `json:"actor"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		current, err := manager.GetMatchById(matchID)
+		if err != nil {
+			respondMatchError(c, err)
+			return
+		}
+
+		// Fields left out keep their current value
+		homeGoals, awayGoals := current.HomeGoals, current.AwayGoals
+		if request.HomeGoals != nil {
+			homeGoals = *request.HomeGoals
+		}
+		if request.AwayGoals != nil {
+			awayGoals = *request.AwayGoals
+		}
+
+		match, err := manager.EditMatchResultById(matchID, homeGoals, awayGoals, request.Reason, request.Actor)
+		if err != nil {
+			respondMatchError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":           "Match updated successfully",
+			"match":             match,
+			"updated_standings": manager.GetStandings(),
+		})
+	})
+
+	// Audit log of result changes
+	router.GET("/matches/:id/history", func(c *gin.Context) {
+		matchID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid match ID",
+			})
+			return
+		}
+
+		match, edits, err := manager.GetMatchHistory(matchID)
+		if err != nil {
+			respondMatchError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"match":       match,
+			"history":     edits,
+			"total_edits": len(edits),
+		})
+	})
+
+	// Restore the score a match had before one of its edits
+	router.POST("/matches/:id/revert", func(c *gin.Context) {
+		matchID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid match ID",
+			})
+			return
+		}
+
+		var request struct {
+			EditID int    `json:"edit_id" binding:"required"`
+			Reason string `json:"reason"`
+			Actor  string `json:"actor"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		match, err := manager.RevertMatchEdit(matchID, request.EditID, request.Reason, request.Actor)
+		if err != nil {
+			respondMatchError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":           "Match result reverted",
+			"match":             match,
+			"updated_standings": manager.GetStandings(),
+		})
+	})
+}
+
+func respondMatchError(c *gin.Context, err error) {
+	if errors.Is(err, league.ErrMatchNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, league.ErrInvalidResult) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Match update failed: " + err.Error(),
+	})
+}
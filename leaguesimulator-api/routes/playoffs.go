@@ -0,0 +1,76 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/league"
+)
+
+// registerPlayoffRoutes adds the post-season playoff endpoints of the league
+func registerPlayoffRoutes(router *gin.Engine) {
+	// Playoff configuration, bracket and champion
+	router.GET("/playoffs", func(c *gin.Context) {
+		status, err := manager.GetPlayoffStatus()
+		if err != nil {
+			respondPlayoffError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, status)
+	})
+
+	// Configure the playoff; enabling it after the regular season has ended starts it straight away
+	router.PUT("/playoffs/config", func(c *gin.Context) {
+		var config league.PlayoffConfig
+		if err := c.ShouldBindJSON(&config); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		if err := manager.ConfigurePlayoffs(config); err != nil {
+			respondPlayoffError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":          "Playoff configuration saved",
+			"config":           manager.Playoffs,
+			"playoffs_started": manager.PlayoffCupID != 0,
+		})
+	})
+
+	// Play the current playoff round
+	router.POST("/playoffs/play-round", func(c *gin.Context) {
+		ties, cup, err := manager.PlayPlayoffRound()
+		if err != nil {
+			respondPlayoffError(c, err)
+			return
+		}
+
+		standings := manager.GetStandings()
+		c.JSON(http.StatusOK, gin.H{
+			"message":      "Playoff round completed",
+			"ties":         ties,
+			"status":       cup.Status,
+			"champion":     cup.Champion,
+			"table_leader": standings[0].Name,
+		})
+	})
+}
+
+func respondPlayoffError(c *gin.Context, err error) {
+	if errors.Is(err, league.ErrInvalidPlayoff) || errors.Is(err, league.ErrInvalidCup) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Playoff operation failed: " + err.Error(),
+	})
+}
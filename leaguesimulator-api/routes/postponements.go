@@ -0,0 +1,112 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/league"
+)
+
+// registerPostponementRoutes adds the endpoints for postponing and rescheduling fixtures
+func registerPostponementRoutes(router *gin.Engine) {
+	// Postponed fixtures still waiting for a new date
+	router.GET("/postponements", func(c *gin.Context) {
+		manager.GetMatches()
+		pending := manager.PendingPostponements()
+		c.JSON(http.StatusOK, gin.H{
+			"postponed":       pending,
+			"total_pending":   len(pending),
+			"season_complete": manager.SeasonComplete(),
+		})
+	})
+
+	// Take a fixture out of its week
+	router.POST("/matches/:id/postpone", func(c *gin.Context) {
+		matchID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid match ID",
+			})
+			return
+		}
+
+		var request struct {
+			Reason string `json:"reason" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		manager.GetMatches()
+		match, err := manager.PostponeMatch(matchID, request.Reason)
+		if err != nil {
+			respondPostponementError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Match postponed",
+			"match":   match,
+		})
+	})
+
+	// Give a postponed fixture a later week and/or kick-off time
+	router.POST("/matches/:id/reschedule", func(c *gin.Context) {
+		matchID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid match ID",
+			})
+			return
+		}
+
+		var request struct {
+			Week    int        `json:"week"`
+			KickOff *time.Time `json:"kick_off"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		manager.GetMatches()
+		match, err := manager.RescheduleMatch(matchID, request.Week, request.KickOff)
+		if err != nil {
+			respondPostponementError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":     "Match rescheduled",
+			"match":       match,
+			"total_weeks": manager.TotalWeeks(),
+		})
+	})
+}
+
+func respondPostponementError(c *gin.Context, err error) {
+	if errors.Is(err, league.ErrMatchNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, league.ErrInvalidPostponement) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Postponement failed: " + err.Error(),
+	})
+}
@@ -0,0 +1,75 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/league"
+)
+
+// registerRatingsRoutes adds the endpoints that fit team ratings to past results
+func registerRatingsRoutes(router *gin.Engine) {
+	// Fit attack, defence and home advantage; "apply": true writes the strengths back
+	router.POST("/ratings/fit", func(c *gin.Context) {
+		var options league.RatingFitOptions
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&options); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+				return
+			}
+		}
+
+		fit, err := manager.FitRatings(options)
+		if err != nil {
+			respondRatingsError(c, err)
+			return
+		}
+		message := "Ratings fitted; send \"apply\": true to update the team strengths"
+		if fit.Applied {
+			message = "Ratings fitted and team strengths updated"
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message":           message,
+			"options":           fit.Options,
+			"intercept":         fit.Fit.Intercept,
+			"home_advantage":    fit.Fit.HomeAdvantage,
+			"teams":             fit.Fit.Teams,
+			"goodness_of_fit":   fit.Fit.Goodness,
+			"matches":           fit.Fit.Matches,
+			"effective_matches": fit.Fit.EffectiveMatches,
+			"iterations":        fit.Fit.Iterations,
+			"converged":         fit.Fit.Converged,
+			"strengths":         fit.Strengths,
+			"unrated_teams":     fit.Unrated,
+			"applied":           fit.Applied,
+		})
+	})
+
+	// The last applied fit
+	router.GET("/ratings", func(c *gin.Context) {
+		fit, err := manager.GetRatings()
+		if err != nil {
+			respondRatingsError(c, err)
+			return
+		}
+		if fit == nil {
+			c.JSON(http.StatusOK, gin.H{
+				"message": "No ratings have been applied yet. POST /ratings/fit with \"apply\": true first.",
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ratings": fit})
+	})
+}
+
+func respondRatingsError(c *gin.Context, err error) {
+	if errors.Is(err, league.ErrInvalidRatings) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Rating fit failed: " + err.Error(),
+	})
+}
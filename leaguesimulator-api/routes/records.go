@@ -0,0 +1,52 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/league"
+)
+
+// registerRecordsRoutes adds the streaks and records endpoint
+func registerRecordsRoutes(router *gin.Engine) {
+	// All-time and per-season records, or one season with ?season=N
+	router.GET("/records", func(c *gin.Context) {
+		if value := c.Query("season"); value != "" {
+			season, err := strconv.Atoi(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "season must be a number"})
+				return
+			}
+			records, err := manager.GetSeasonRecords(season)
+			if err != nil {
+				respondRecordsError(c, err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"records": records})
+			return
+		}
+
+		records, err := manager.GetRecords()
+		if err != nil {
+			respondRecordsError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"all_time": records.AllTime,
+			"seasons":  records.Seasons,
+		})
+	})
+}
+
+func respondRecordsError(c *gin.Context, err error) {
+	if errors.Is(err, league.ErrSeasonNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Failed to load records: " + err.Error(),
+	})
+}
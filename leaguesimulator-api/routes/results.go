@@ -0,0 +1,120 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/db"
+	"leaguesimulator/league"
+)
+
+// registerResultRoutes adds the league mode switch and manual result entry endpoints
+func registerResultRoutes(router *gin.Engine) {
+	// Whether weeks are simulated or results are entered by hand
+	router.GET("/league/mode", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"mode": manager.Mode,
+		})
+	})
+
+	router.PUT("/league/mode", func(c *gin.Context) {
+		var request struct {
+			Mode string `json:"mode" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		if err := manager.SetLeagueMode(request.Mode); err != nil {
+			respondResultError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "League mode updated",
+			"mode":    manager.Mode,
+		})
+	})
+
+	// Enter the result of a fixture with its scorers
+	router.PUT("/matches/:id/result", func(c *gin.Context) {
+		matchID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid match ID",
+			})
+			return
+		}
+
+		var submission league.ResultSubmission
+		if err := c.ShouldBindJSON(&submission); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		manager.GetMatches()
+		match, scorers, err := manager.SubmitResult(matchID, submission)
+		if err != nil {
+			respondResultError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":      "Result recorded",
+			"match":        match,
+			"scorers":      scorers,
+			"current_week": manager.Week,
+			"standings":    manager.GetStandings(),
+		})
+	})
+
+	// Scorers of a match
+	router.GET("/matches/:id/scorers", func(c *gin.Context) {
+		matchID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid match ID",
+			})
+			return
+		}
+
+		scorers, err := db.GetMatchGoals(matchID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to load scorers: " + err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"match_id": matchID,
+			"scorers":  scorers,
+		})
+	})
+}
+
+func respondResultError(c *gin.Context, err error) {
+	if errors.Is(err, league.ErrMatchNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, league.ErrInvalidResult) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Result entry failed: " + err.Error(),
+	})
+}
@@ -0,0 +1,58 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/db"
+	"leaguesimulator/league"
+)
+
+// registerRewindRoutes adds the endpoints that take the league back to an earlier week
+func registerRewindRoutes(router *gin.Engine) {
+	// Undo every result after the given week
+	router.POST("/rewind", func(c *gin.Context) {
+		var request struct {
+			Week *int `json:"week" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		summary, err := manager.RewindToWeek(*request.Week)
+		respondRewind(c, summary, err)
+	})
+
+	// Undo the last played week
+	router.POST("/undo-week", func(c *gin.Context) {
+		summary, err := manager.UndoLastWeek()
+		respondRewind(c, summary, err)
+	})
+}
+
+func respondRewind(c *gin.Context, summary db.RewindSummary, err error) {
+	if errors.Is(err, league.ErrInvalidRewind) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Rewind failed: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "League rewound",
+		"current_week": manager.Week,
+		"removed":      summary,
+		"standings":    manager.GetStandings(),
+	})
+}
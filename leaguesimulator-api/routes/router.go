@@ -1,12 +1,14 @@
 package routes
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 
+	"leaguesimulator/export"
 	"leaguesimulator/league"
 	"leaguesimulator/models"
 	"leaguesimulator/prediction"
@@ -15,41 +17,20 @@ import (
 var manager league.LeagueManager
 var predictionService *prediction.AdvancedPredictionService
 
-// adjustPredictionPercentages modifies prediction percentages based on current week and standings
-func adjustPredictionPercentages(predictions *prediction.ComprehensivePrediction, currentWeek int, standings []league.TeamStanding) {
-	if len(standings) == 0 || predictions == nil {
+// adjustPredictionPercentages zeroes the title odds of mathematically
+// eliminated teams and spreads their share over the teams still in contention
+func adjustPredictionPercentages(predictions *prediction.ComprehensivePrediction, status league.SeasonStatus) {
+	if len(status.Teams) == 0 || predictions == nil {
 		return
 	}
 
-	// Get the top team's points
-	topPoints := standings[0].Points
-
-	// Create a map of eligible teams based on the week rules
+	// Create a map of teams that can still win the title
 	eligibleTeams := make(map[string]bool)
-
-	switch {
-	case currentWeek >= 6:
-		// Week 6: Only the team with highest score gets 100%
-		for _, team := range standings {
-			eligibleTeams[team.Name] = (team.Points == topPoints)
-		}
-
-	case currentWeek >= 5:
-		// Week 5: Eliminate teams more than 3 points behind
-		for _, team := range standings {
-			eligibleTeams[team.Name] = (topPoints-team.Points <= 3)
-		}
-
-	case currentWeek >= 4:
-		// Week 4: Eliminate teams more than 6 points behind
-		for _, team := range standings {
-			eligibleTeams[team.Name] = (topPoints-team.Points <= 6)
-		}
-
-	default:
-		// Before week 4: All teams are eligible
-		for _, team := range standings {
-			eligibleTeams[team.Name] = true
+	champion := ""
+	for _, team := range status.Teams {
+		eligibleTeams[team.Team] = !team.Eliminated
+		if team.ClinchedTitle {
+			champion = team.Team
 		}
 	}
 
@@ -65,17 +46,17 @@ func adjustPredictionPercentages(predictions *prediction.ComprehensivePrediction
 	}
 
 	// Redistribute probabilities
-	if currentWeek >= 6 {
-		// Week 6: Winner takes all
+	if champion != "" {
+		// The title is clinched: winner takes all
 		for teamName := range predictions.SeasonSimulation.ChampionshipProbabilities {
-			if eligibleTeams[teamName] && standings[0].Name == teamName {
+			if teamName == champion {
 				predictions.SeasonSimulation.ChampionshipProbabilities[teamName] = 100.0
 			} else {
 				predictions.SeasonSimulation.ChampionshipProbabilities[teamName] = 0.0
 			}
 		}
 	} else if totalEligibleProb > 0 {
-		// Weeks 4-5: Redistribute proportionally among eligible teams
+		// Redistribute proportionally among teams still in contention
 		redistributionFactor := 100.0 / totalEligibleProb
 
 		for teamName := range predictions.SeasonSimulation.ChampionshipProbabilities {
@@ -96,10 +77,13 @@ func adjustPredictionPercentages(predictions *prediction.ComprehensivePrediction
 		}
 	}
 
-	if eliminatedCount > 0 {
+	if champion != "" {
+		predictions.SeasonSimulation.Methodology = fmt.Sprintf(
+			"Adjusted probabilities: %s has mathematically clinched the title", champion)
+	} else if eliminatedCount > 0 {
 		predictions.SeasonSimulation.Methodology = fmt.Sprintf(
-			"Adjusted probabilities: %d team(s) eliminated based on week %d rules",
-			eliminatedCount, currentWeek)
+			"Adjusted probabilities: %d team(s) mathematically eliminated from the title race",
+			eliminatedCount)
 	}
 }
 
@@ -132,45 +116,141 @@ func SetupRouter() *gin.Engine {
 	// Initialize league
 	router.POST("/init-league", func(c *gin.Context) {
 		manager.InitLeague()
+
+		teamNames := []string{}
+		for _, team := range manager.Teams {
+			teamNames = append(teamNames, team.Name)
+		}
+		totalWeeks := manager.TotalWeeks()
+
 		c.JSON(http.StatusOK, gin.H{
-			"message": "League initialized with 4 teams",
-			"teams":   []string{"Lions", "Tigers", "Bears", "Wolves"},
+			"message": fmt.Sprintf("League initialized with %d teams", len(teamNames)),
+			"teams":   teamNames,
 			"season_structure": gin.H{
-				"total_weeks":      3,
-				"matches_per_week": 2,
-				"total_matches":    6,
+				"total_weeks": totalWeeks,
+				"matches_per_week": func() int {
+					if totalWeeks == 0 {
+						return 0
+					}
+					return len(manager.Matches) / totalWeeks
+				}(),
+				"total_matches": len(manager.Matches),
 			},
 		})
 	})
 
 	// Play next week matches
 	router.POST("/next-week", func(c *gin.Context) {
+		if manager.Mode == league.LeagueModeManual {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "The league is in manual mode, submit results with PUT /matches/:id/result",
+			})
+			return
+		}
+
 		matches := manager.PlayNextWeek()
 		if matches == nil {
-			c.JSON(http.StatusOK, gin.H{
+			if pending := manager.PendingPostponements(); len(pending) > 0 {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":     fmt.Sprintf("%d postponed fixtures must be rescheduled before the season can end", len(pending)),
+					"postponed": pending,
+				})
+				return
+			}
+			status := manager.GetSeasonStatus(0)
+			response := gin.H{
 				"message":         "League finished",
 				"final_standings": manager.GetStandings(),
-			})
+				"season_status":   status,
+			}
+			for _, team := range status.Teams {
+				if team.ClinchedTitle {
+					response["message"] = fmt.Sprintf("League finished, %s are champions", team.Team)
+					response["champion"] = team.Team
+				}
+			}
+			if playoffs, err := manager.GetPlayoffStatus(); err == nil && playoffs.Status != league.PlayoffStatusDisabled {
+				response["playoffs"] = playoffs
+			}
+			c.JSON(http.StatusOK, response)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{
+		response := gin.H{
 			"week":    manager.Week,
 			"matches": matches,
 			"message": "Week completed successfully",
-		})
+		}
+		if pending := manager.PendingPostponements(); len(pending) > 0 && manager.Week >= manager.TotalWeeks() {
+			response["message"] = fmt.Sprintf("Week completed, %d postponed fixtures still need a new date", len(pending))
+			response["postponed"] = pending
+		} else if manager.SeasonComplete() && manager.PlayoffCupID != 0 {
+			response["message"] = "Regular season completed, playoffs started"
+			if playoffs, err := manager.GetPlayoffStatus(); err == nil {
+				response["playoffs"] = playoffs
+			}
+		}
+		c.JSON(http.StatusOK, response)
 	})
 
 	// Get current standings with enhanced info
 	router.GET("/standings", func(c *gin.Context) {
+		format, ok := exportFormat(c)
+		if !ok {
+			return
+		}
+		topN := 0
+		if value := c.Query("top"); value != "" {
+			// At least one team must be left outside the top places
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || parsed >= len(manager.Teams) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": fmt.Sprintf("top must be a number from 1 to %d", len(manager.Teams)-1),
+				})
+				return
+			}
+			topN = parsed
+		}
+
+		// Table variants: ?week=N, ?venue=home|away, ?half=first|second, ?form=N
+		if options, ok, err := tableOptions(c); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		} else if ok {
+			standings, err := manager.GetTable(options)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if format != export.FormatJSON {
+				writeTable(c, standingsTable(standings), format)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{
+				"current_week": manager.Week,
+				"table":        options,
+				"standings":    standings,
+				"total_teams":  len(standings),
+			})
+			return
+		}
+
 		standings := manager.GetStandings()
+		if format != export.FormatJSON {
+			writeTable(c, standingsTable(standings), format)
+			return
+		}
 		c.JSON(http.StatusOK, gin.H{
-			"current_week": manager.Week,
-			"standings":    standings,
-			"total_teams":  len(standings),
+			"current_week":  manager.Week,
+			"standings":     standings,
+			"season_status": manager.GetSeasonStatus(topN),
+			"total_teams":   len(standings),
 			"league_status": func() string {
-				if manager.Week >= 6 {
+				if manager.SeasonComplete() {
 					return "completed"
 				}
+				if manager.Week >= manager.TotalWeeks() {
+					return "awaiting_postponed"
+				}
 				return "ongoing"
 			}(),
 		})
@@ -178,12 +258,25 @@ func SetupRouter() *gin.Engine {
 
 	// Get all matches with statistics
 	router.GET("/matches", func(c *gin.Context) {
+		format, ok := exportFormat(c)
+		if !ok {
+			return
+		}
 		matches := manager.GetMatches()
+		if format != export.FormatJSON {
+			writeTable(c, matchesTable("matches", matches), format)
+			return
+		}
 
-		// Calculate match statistics
+		// Calculate match statistics over played matches
 		totalGoals := 0
 		highestScore := 0
+		playedMatches := 0
 		for _, match := range matches {
+			if !match.Played {
+				continue
+			}
+			playedMatches++
 			matchGoals := match.HomeGoals + match.AwayGoals
 			totalGoals += matchGoals
 			if matchGoals > highestScore {
@@ -194,13 +287,14 @@ func SetupRouter() *gin.Engine {
 		c.JSON(http.StatusOK, gin.H{
 			"matches": matches,
 			"statistics": gin.H{
-				"total_matches": len(matches),
-				"total_goals":   totalGoals,
+				"total_matches":  len(matches),
+				"played_matches": playedMatches,
+				"total_goals":    totalGoals,
 				"average_goals_per_match": func() float64 {
-					if len(matches) == 0 {
+					if playedMatches == 0 {
 						return 0.0
 					}
-					return float64(totalGoals) / float64(len(matches))
+					return float64(totalGoals) / float64(playedMatches)
 				}(),
 				"highest_scoring_match": highestScore,
 			},
@@ -252,9 +346,8 @@ func SetupRouter() *gin.Engine {
 			return
 		}
 
-		// Apply dynamic prediction adjustments based on current standings and week
-		standings := manager.GetStandings()
-		adjustPredictionPercentages(predictions, manager.Week, standings)
+		// Apply exact clinch and elimination status from the remaining fixtures
+		adjustPredictionPercentages(predictions, manager.GetSeasonStatus(0))
 
 		c.JSON(http.StatusOK, predictions)
 	})
@@ -293,11 +386,11 @@ func SetupRouter() *gin.Engine {
 		tempPrediction := &prediction.ComprehensivePrediction{
 			SeasonSimulation: *outlook,
 		}
-		adjustPredictionPercentages(tempPrediction, manager.Week, standings)
+		adjustPredictionPercentages(tempPrediction, manager.GetSeasonStatus(0))
 
 		c.JSON(http.StatusOK, gin.H{
 			"season_outlook":       tempPrediction.SeasonSimulation,
-			"note":                 fmt.Sprintf("Adjusted for week %d elimination rules - Based on 1000+ Monte Carlo simulations", manager.Week),
+			"note":                 "Adjusted for mathematical elimination - Based on 1000+ Monte Carlo simulations",
 			"current_week":         manager.Week,
 			"standings_considered": len(standings),
 		})
@@ -314,6 +407,13 @@ func SetupRouter() *gin.Engine {
 
 	// Play all remaining matches with detailed tracking
 	router.POST("/play-all", func(c *gin.Context) {
+		if manager.Mode == league.LeagueModeManual {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "The league is in manual mode, submit results with PUT /matches/:id/result",
+			})
+			return
+		}
+
 		var allWeeks []gin.H
 		weekCount := manager.Week
 
@@ -334,16 +434,20 @@ func SetupRouter() *gin.Engine {
 
 		finalStandings := manager.GetStandings()
 		champion := ""
-		if len(finalStandings) > 0 {
+		message := "All matches completed"
+		if !manager.SeasonComplete() {
+			message = "All scheduled matches completed, postponed fixtures still need a new date"
+		} else if len(finalStandings) > 0 {
 			champion = finalStandings[0].Name
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"message":         "All matches completed",
+			"message":         message,
 			"weeks_played":    allWeeks,
 			"final_standings": finalStandings,
 			"champion":        champion,
 			"total_weeks":     len(allWeeks),
+			"postponed":       manager.PendingPostponements(),
 		})
 	})
 
@@ -383,7 +487,15 @@ func SetupRouter() *gin.Engine {
 
 	// Get future fixtures with predictions
 	router.GET("/fixtures", func(c *gin.Context) {
+		format, ok := exportFormat(c)
+		if !ok {
+			return
+		}
 		fixtures := manager.GetFutureFixtures()
+		if format != export.FormatJSON {
+			writeTable(c, upcomingTable(fixtures), format)
+			return
+		}
 		c.JSON(http.StatusOK, gin.H{
 			"upcoming_fixtures": fixtures,
 			"total_remaining":   len(fixtures),
@@ -400,6 +512,7 @@ func SetupRouter() *gin.Engine {
 			Score1 *int   `json:"score1" binding:"required"`
 			Score2 *int   `json:"score2" binding:"required"`
 			Reason string `json:"reason,omitempty"`
+			Actor  string `json:"actor,omitempty"`
 		}
 
 		if err := c.ShouldBindJSON(&editRequest); err != nil {
@@ -415,6 +528,8 @@ func SetupRouter() *gin.Engine {
 			editRequest.Team2,
 			*editRequest.Score1,
 			*editRequest.Score2,
+			editRequest.Reason,
+			editRequest.Actor,
 		)
 
 		if !success {
@@ -438,6 +553,10 @@ func SetupRouter() *gin.Engine {
 
 	// Team performance analysis
 	router.GET("/team/:name/analysis", func(c *gin.Context) {
+		format, ok := exportFormat(c)
+		if !ok {
+			return
+		}
 		teamName := c.Param("name")
 
 		// Find team in current standings
@@ -467,7 +586,7 @@ func SetupRouter() *gin.Engine {
 		goalsInMatches := []int{}
 
 		for _, match := range matches {
-			if match.HomeTeam == teamName || match.AwayTeam == teamName {
+			if match.Played && (match.HomeTeam == teamName || match.AwayTeam == teamName) {
 				teamMatches = append(teamMatches, match)
 				if match.HomeTeam == teamName {
 					goalsInMatches = append(goalsInMatches, match.HomeGoals)
@@ -477,6 +596,11 @@ func SetupRouter() *gin.Engine {
 			}
 		}
 
+		if format != export.FormatJSON {
+			writeTable(c, teamMatchesTable(teamName, teamMatches), format)
+			return
+		}
+
 		// Calculate form (last 3 matches)
 		form := "N/A"
 		if len(teamMatches) >= 3 {
@@ -569,19 +693,26 @@ func SetupRouter() *gin.Engine {
 
 		// Calculate league-wide statistics
 		totalGoals := 0
-		totalMatches := len(matches)
+		totalMatches := 0
+		for _, match := range matches {
+			if match.Played {
+				totalMatches++
+			}
+		}
 		highestScoringTeam := standings[0].Name
 		bestDefense := standings[0].Name
 
+		mostScored := standings[0].GoalsFor
+		fewestConceded := standings[0].GoalsAgainst
 		for _, team := range standings {
 			totalGoals += team.GoalsFor
-			if team.GoalsFor > standings[0].GoalsFor {
+			if team.GoalsFor > mostScored {
 				highestScoringTeam = team.Name
+				mostScored = team.GoalsFor
 			}
-			for _, defTeam := range standings {
-				if defTeam.GoalsAgainst < team.GoalsAgainst {
-					bestDefense = defTeam.Name
-				}
+			if team.GoalsAgainst < fewestConceded {
+				bestDefense = team.Name
+				fewestConceded = team.GoalsAgainst
 			}
 		}
 
@@ -603,7 +734,7 @@ func SetupRouter() *gin.Engine {
 			},
 			"standings": standings,
 			"competition_status": func() string {
-				if manager.Week >= 3 {
+				if manager.SeasonComplete() {
 					return "Season Complete"
 				}
 				return "Season In Progress"
@@ -616,56 +747,56 @@ func SetupRouter() *gin.Engine {
 		team1 := c.Param("team1")
 		team2 := c.Param("team2")
 
-		matches := manager.GetMatches()
-		h2hMatches := []models.Match{}
-		team1Wins := 0
-		team2Wins := 0
-		draws := 0
-
-		for _, match := range matches {
-			if (match.HomeTeam == team1 && match.AwayTeam == team2) ||
-				(match.HomeTeam == team2 && match.AwayTeam == team1) {
-				h2hMatches = append(h2hMatches, match)
-
-				if match.HomeTeam == team1 {
-					if match.HomeGoals > match.AwayGoals {
-						team1Wins++
-					} else if match.HomeGoals < match.AwayGoals {
-						team2Wins++
-					} else {
-						draws++
-					}
-				} else {
-					if match.AwayGoals > match.HomeGoals {
-						team1Wins++
-					} else if match.AwayGoals < match.HomeGoals {
-						team2Wins++
-					} else {
-						draws++
-					}
+		// Optional filters: ?from_season=N&to_season=N&venue=home|away (venue of team1)
+		filter := league.HeadToHeadFilter{Venue: c.Query("venue")}
+		for name, target := range map[string]*int{"from_season": &filter.FromSeason, "to_season": &filter.ToSeason} {
+			if value := c.Query(name); value != "" {
+				season, err := strconv.Atoi(value)
+				if err != nil || season == 0 {
+					c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be a season number"})
+					return
 				}
+				*target = season
 			}
 		}
 
+		h2h, err := manager.GetHeadToHead(team1, team2, filter)
+		if errors.Is(err, league.ErrInvalidHeadToHead) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to load head-to-head: " + err.Error(),
+			})
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"teams": gin.H{
 				"team1": team1,
 				"team2": team2,
 			},
-			"head_to_head_record": gin.H{
-				"matches_played": len(h2hMatches),
-				"team1_wins":     team1Wins,
-				"team2_wins":     team2Wins,
-				"draws":          draws,
+			"filter":              h2h.Filter,
+			"head_to_head_record": h2h.Record,
+			"home_away": gin.H{
+				"team1_at_home": h2h.Team1AtHome,
+				"team2_at_home": h2h.Team2AtHome,
+			},
+			"seasons": h2h.Seasons,
+			"biggest_wins": gin.H{
+				"team1": h2h.Team1Best,
+				"team2": h2h.Team2Best,
 			},
-			"matches": h2hMatches,
+			"recent_form": h2h.RecentForm,
+			"matches":     h2h.Matches,
 			"summary": func() string {
-				if len(h2hMatches) == 0 {
+				if h2h.Record.MatchesPlayed == 0 {
 					return "No matches played between these teams yet"
 				}
-				if team1Wins > team2Wins {
+				if h2h.Record.Team1Wins > h2h.Record.Team2Wins {
 					return team1 + " leads the head-to-head record"
-				} else if team2Wins > team1Wins {
+				} else if h2h.Record.Team2Wins > h2h.Record.Team1Wins {
 					return team2 + " leads the head-to-head record"
 				}
 				return "Even head-to-head record"
@@ -673,6 +804,27 @@ func SetupRouter() *gin.Engine {
 		})
 	})
 
+	registerCupRoutes(router)
+	registerTournamentRoutes(router)
+	registerDivisionRoutes(router)
+	registerPlayoffRoutes(router)
+	registerCalendarRoutes(router)
+	registerScheduleRoutes(router)
+	registerPostponementRoutes(router)
+	registerResultRoutes(router)
+	registerMatchRoutes(router)
+	registerRewindRoutes(router)
+	registerSnapshotRoutes(router)
+	registerScenarioRoutes(router)
+	registerStandingsRoutes(router)
+	registerRecordsRoutes(router)
+	registerSeasonRoutes(router)
+	registerImportRoutes(router)
+	registerExportRoutes(router)
+	registerRatingsRoutes(router)
+	registerMarketsRoutes(router)
+	registerBacktestRoutes(router)
+
 	return router
 }
 
@@ -682,7 +834,7 @@ func corsMiddleware() gin.HandlerFunc {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -0,0 +1,47 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/league"
+)
+
+// registerScenarioRoutes adds the what-if scenario endpoint
+func registerScenarioRoutes(router *gin.Engine) {
+	// Title odds and standings if some upcoming fixtures end with the given scores
+	router.POST("/what-if", func(c *gin.Context) {
+		var request struct {
+			Results     []league.HypotheticalResult `json:"results" binding:"required"`
+			Simulations int                         `json:"simulations"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		result, err := manager.RunScenario(request.Results, request.Simulations)
+		if errors.Is(err, league.ErrInvalidScenario) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Scenario failed: " + err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"current_week": manager.Week,
+			"scenario":     result,
+			"note":         "Hypothetical results are not saved; odds come from Monte Carlo runs of the remaining fixtures",
+		})
+	})
+}
@@ -0,0 +1,76 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/league"
+)
+
+// registerScheduleRoutes adds the fixture scheduling constraint endpoints
+func registerScheduleRoutes(router *gin.Engine) {
+	// Current scheduling constraints
+	router.GET("/schedule/constraints", func(c *gin.Context) {
+		c.JSON(http.StatusOK, manager.Constraints)
+	})
+
+	// Replace the scheduling constraints used for the next fixture generation.
+	// Fields left out of the request keep their current values.
+	router.PUT("/schedule/constraints", func(c *gin.Context) {
+		constraints := manager.SchedulingConstraints()
+		if err := c.ShouldBindJSON(&constraints); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		if err := manager.ConfigureScheduling(constraints); err != nil {
+			respondScheduleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":     "Scheduling constraints saved",
+			"constraints": manager.Constraints,
+		})
+	})
+
+	// Check the stored fixtures against the constraints
+	router.GET("/schedule/check", func(c *gin.Context) {
+		c.JSON(http.StatusOK, manager.CheckSchedule())
+	})
+
+	// Build a new constrained fixture list before the season starts
+	router.POST("/schedule/generate", func(c *gin.Context) {
+		report, err := manager.RegenerateFixtures()
+		if err != nil {
+			respondScheduleError(c, err)
+			return
+		}
+
+		message := "Fixtures generated, all constraints met"
+		if !report.Satisfied {
+			message = "Fixtures generated, some constraints could not be met"
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message":  message,
+			"report":   report,
+			"fixtures": manager.GetFutureFixtures(),
+		})
+	})
+}
+
+func respondScheduleError(c *gin.Context, err error) {
+	if errors.Is(err, league.ErrInvalidSchedule) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Scheduling failed: " + err.Error(),
+	})
+}
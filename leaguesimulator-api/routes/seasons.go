@@ -0,0 +1,77 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/db"
+	"leaguesimulator/league"
+)
+
+// registerSeasonRoutes adds the season archive, all-time table and honours endpoints
+func registerSeasonRoutes(router *gin.Engine) {
+	// Final table of every season and the running one
+	router.GET("/seasons", func(c *gin.Context) {
+		archive, err := manager.GetSeasonArchive()
+		if err != nil {
+			respondSeasonError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"current_season": db.CurrentSeason,
+			"seasons":        archive,
+		})
+	})
+
+	// Archive the finished season and start the next one
+	router.POST("/seasons/new", func(c *gin.Context) {
+		finished, err := manager.StartNewSeason()
+		if err != nil {
+			respondSeasonError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message":         "New season started",
+			"current_season":  db.CurrentSeason,
+			"archived_season": finished,
+			"fixtures":        len(manager.Matches),
+		})
+	})
+
+	// Combined table of every season
+	router.GET("/all-time-table", func(c *gin.Context) {
+		table, err := manager.GetAllTimeTable()
+		if err != nil {
+			respondSeasonError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"current_season": db.CurrentSeason,
+			"table":          table,
+		})
+	})
+
+	// League titles and runner-up finishes per club
+	router.GET("/honours", func(c *gin.Context) {
+		honours, err := manager.GetHonours()
+		if err != nil {
+			respondSeasonError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"honours": honours,
+		})
+	})
+}
+
+func respondSeasonError(c *gin.Context, err error) {
+	if errors.Is(err, league.ErrInvalidSeason) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Season archive failed: " + err.Error(),
+	})
+}
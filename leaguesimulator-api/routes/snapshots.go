@@ -0,0 +1,173 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/league"
+)
+
+// registerSnapshotRoutes adds the league snapshot and random seed endpoints
+func registerSnapshotRoutes(router *gin.Engine) {
+	// List saved snapshots
+	router.GET("/snapshots", func(c *gin.Context) {
+		snapshots, err := league.ListSnapshots()
+		if err != nil {
+			respondSnapshotError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"snapshots": snapshots,
+			"total":     len(snapshots),
+		})
+	})
+
+	// Save the current league state under a name
+	router.POST("/snapshots", func(c *gin.Context) {
+		var request struct {
+			Name string `json:"name" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		snapshot, err := manager.CreateSnapshot(request.Name)
+		if err != nil {
+			respondSnapshotError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"message":  "Snapshot created",
+			"snapshot": snapshot,
+		})
+	})
+
+	// A snapshot with its full document
+	router.GET("/snapshots/:id", func(c *gin.Context) {
+		snapshotID, ok := snapshotIDParam(c)
+		if !ok {
+			return
+		}
+
+		snapshot, document, err := league.GetSnapshot(snapshotID)
+		if err != nil {
+			respondSnapshotError(c, err)
+			return
+		}
+		snapshot.Document = nil
+
+		c.JSON(http.StatusOK, gin.H{
+			"snapshot": snapshot,
+			"document": document,
+		})
+	})
+
+	// Replace the current league with a snapshot
+	router.POST("/snapshots/:id/restore", func(c *gin.Context) {
+		snapshotID, ok := snapshotIDParam(c)
+		if !ok {
+			return
+		}
+
+		snapshot, err := manager.RestoreSnapshot(snapshotID, c.Query("remove_teams") == "true")
+		if err != nil {
+			respondSnapshotError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":      "Snapshot restored",
+			"snapshot":     snapshot,
+			"current_week": manager.Week,
+			"standings":    manager.GetStandings(),
+		})
+	})
+
+	router.DELETE("/snapshots/:id", func(c *gin.Context) {
+		snapshotID, ok := snapshotIDParam(c)
+		if !ok {
+			return
+		}
+
+		if err := league.DeleteSnapshot(snapshotID); err != nil {
+			respondSnapshotError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Snapshot deleted",
+		})
+	})
+
+	// Random seed used to simulate matches (0 = a fresh seed for every match)
+	router.GET("/league/seed", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"seed": manager.Seed,
+		})
+	})
+
+	router.PUT("/league/seed", func(c *gin.Context) {
+		var request struct {
+			Seed *int64 `json:"seed" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		if err := manager.SetRandomSeed(*request.Seed); err != nil {
+			respondSnapshotError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Random seed updated",
+			"seed":    manager.Seed,
+		})
+	})
+}
+
+func snapshotIDParam(c *gin.Context) (int, bool) {
+	snapshotID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid snapshot ID",
+		})
+		return 0, false
+	}
+	return snapshotID, true
+}
+
+func respondSnapshotError(c *gin.Context, err error) {
+	if errors.Is(err, league.ErrRemovesHistory) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, league.ErrSnapshotNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, league.ErrInvalidSnapshot) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Snapshot operation failed: " + err.Error(),
+	})
+}
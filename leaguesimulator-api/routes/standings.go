@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/league"
+)
+
+// registerStandingsRoutes adds the week-by-week league table endpoints
+func registerStandingsRoutes(router *gin.Engine) {
+	// Table after every played week and each team's position timeline
+	router.GET("/standings/history", func(c *gin.Context) {
+		history := manager.GetStandingsHistory()
+		c.JSON(http.StatusOK, gin.H{
+			"current_week": manager.Week,
+			"weeks":        history.Weeks,
+			"timelines":    history.Timelines,
+		})
+	})
+}
+
+// tableOptions reads the table variant query parameters of /standings. The
+// second result is false when none was given.
+func tableOptions(c *gin.Context) (league.TableOptions, bool, error) {
+	options := league.TableOptions{
+		Venue: c.Query("venue"),
+		Half:  c.Query("half"),
+	}
+	for name, target := range map[string]*int{"week": &options.Week, "form": &options.Form} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 1 {
+			return options, false, fmt.Errorf("%s must be a positive number", name)
+		}
+		*target = number
+	}
+	return options, options != league.TableOptions{}, nil
+}
@@ -0,0 +1,177 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"leaguesimulator/league"
+	"leaguesimulator/models"
+)
+
+// registerTournamentRoutes adds the group stage plus knockout tournament endpoints
+func registerTournamentRoutes(router *gin.Engine) {
+	// List all tournaments
+	router.GET("/tournaments", func(c *gin.Context) {
+		tournaments, err := league.ListTournaments()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to load tournaments: " + err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"tournaments": tournaments,
+			"total":       len(tournaments),
+		})
+	})
+
+	// Create a tournament from existing teams
+	router.POST("/tournaments", func(c *gin.Context) {
+		var tournamentRequest struct {
+			Name               string   `json:"name" binding:"required"`
+			Teams              []string `json:"teams,omitempty"`
+			Groups             int      `json:"groups" binding:"required"`
+			QualifiersPerGroup int      `json:"qualifiers_per_group,omitempty"`
+			ExtraQualifiers    int      `json:"extra_qualifiers,omitempty"`
+			KnockoutLegs       int      `json:"knockout_legs,omitempty"`
+		}
+
+		if err := c.ShouldBindJSON(&tournamentRequest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: " + err.Error(),
+			})
+			return
+		}
+
+		tournament, err := league.CreateTournament(
+			tournamentRequest.Name,
+			tournamentRequest.Teams,
+			tournamentRequest.Groups,
+			tournamentRequest.QualifiersPerGroup,
+			tournamentRequest.ExtraQualifiers,
+			tournamentRequest.KnockoutLegs,
+		)
+		if err != nil {
+			respondTournamentError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"message":    "Tournament created",
+			"tournament": tournament,
+		})
+	})
+
+	// Combined tournament view: groups, tables, qualifiers and knockout bracket
+	router.GET("/tournaments/:id", func(c *gin.Context) {
+		tournament, ok := loadTournament(c)
+		if !ok {
+			return
+		}
+
+		view, err := league.GetTournamentView(tournament)
+		if err != nil {
+			respondTournamentError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, view)
+	})
+
+	// Group tables only
+	router.GET("/tournaments/:id/groups", func(c *gin.Context) {
+		tournament, ok := loadTournament(c)
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"tournament_id": tournament.ID,
+			"status":        tournament.Status,
+			"group_tables":  league.GroupTables(tournament),
+		})
+	})
+
+	// Draw the groups from the seeding pots
+	router.POST("/tournaments/:id/draw", func(c *gin.Context) {
+		tournament, ok := loadTournament(c)
+		if !ok {
+			return
+		}
+
+		if err := league.DrawTournamentGroups(tournament); err != nil {
+			respondTournamentError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Groups drawn",
+			"groups":  tournament.Groups,
+		})
+	})
+
+	// Play the next group matchday or knockout round
+	router.POST("/tournaments/:id/play-round", func(c *gin.Context) {
+		tournament, ok := loadTournament(c)
+		if !ok {
+			return
+		}
+
+		round, err := league.PlayTournamentRound(tournament)
+		if err != nil {
+			respondTournamentError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "Round completed",
+			"round":    round,
+			"status":   tournament.Status,
+			"champion": tournament.Champion,
+		})
+	})
+}
+
+// loadTournament reads the tournament named by the :id parameter, writing the error response itself
+func loadTournament(c *gin.Context) (*models.Tournament, bool) {
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid tournament ID format",
+		})
+		return nil, false
+	}
+
+	tournament, err := league.GetTournament(tournamentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Tournament not found",
+		})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to load tournament: " + err.Error(),
+		})
+		return nil, false
+	}
+
+	return tournament, true
+}
+
+func respondTournamentError(c *gin.Context, err error) {
+	if errors.Is(err, league.ErrInvalidTournament) || errors.Is(err, league.ErrInvalidCup) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Tournament operation failed: " + err.Error(),
+	})
+}
@@ -2,6 +2,14 @@ DROP DATABASE IF EXISTS leaguesimulator;
 CREATE DATABASE leaguesimulator;
 USE leaguesimulator;
 
+CREATE TABLE venues (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    city VARCHAR(100) NOT NULL DEFAULT '',
+    capacity INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
 CREATE TABLE teams (
     name VARCHAR(100) PRIMARY KEY,
     points INT DEFAULT 0,
@@ -12,8 +20,10 @@ CREATE TABLE teams (
     goals_for INT DEFAULT 0,
     goals_against INT DEFAULT 0,
     strength INT NOT NULL,
+    venue_id INT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CONSTRAINT fk_team_venue FOREIGN KEY (venue_id) REFERENCES venues(id) ON DELETE SET NULL
 );
 
 CREATE TABLE matches (
@@ -24,12 +34,46 @@ CREATE TABLE matches (
     home_goals INT DEFAULT 0,
     away_goals INT DEFAULT 0,
     played BOOLEAN DEFAULT FALSE,
+    kick_off DATETIME NULL,
+    venue_id INT NULL,
+    postponed BOOLEAN DEFAULT FALSE,
+    postponement_reason VARCHAR(255) NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     INDEX idx_week (week),
     INDEX idx_teams (home_team_name, away_team_name),
     CONSTRAINT fk_home_team FOREIGN KEY (home_team_name) REFERENCES teams(name) ON DELETE CASCADE ON UPDATE CASCADE,
-    CONSTRAINT fk_away_team FOREIGN KEY (away_team_name) REFERENCES teams(name) ON DELETE CASCADE ON UPDATE CASCADE
+    CONSTRAINT fk_away_team FOREIGN KEY (away_team_name) REFERENCES teams(name) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT fk_match_venue FOREIGN KEY (venue_id) REFERENCES venues(id) ON DELETE SET NULL
+);
+
+CREATE TABLE match_goals (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    match_id INT NOT NULL,
+    team_name VARCHAR(100) NOT NULL,
+    player_name VARCHAR(100) NOT NULL,
+    minute INT NULL,
+    own_goal BOOLEAN DEFAULT FALSE,
+    penalty BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_match_goals_match (match_id),
+    CONSTRAINT fk_goal_match FOREIGN KEY (match_id) REFERENCES matches(id) ON DELETE CASCADE,
+    CONSTRAINT fk_goal_team FOREIGN KEY (team_name) REFERENCES teams(name) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE match_result_edits (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    match_id INT NOT NULL,
+    old_home_goals INT NOT NULL,
+    old_away_goals INT NOT NULL,
+    new_home_goals INT NOT NULL,
+    new_away_goals INT NOT NULL,
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    actor VARCHAR(100) NOT NULL,
+    revert_of INT NULL,
+    edited_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_result_edits_match (match_id),
+    CONSTRAINT fk_result_edit_match FOREIGN KEY (match_id) REFERENCES matches(id) ON DELETE CASCADE
 );
 
 CREATE TABLE predictions (
@@ -55,6 +99,219 @@ CREATE TABLE historical_matches (
     FOREIGN KEY (away_team_name) REFERENCES teams(name) ON DELETE CASCADE ON UPDATE CASCADE
 );
 
+-- Final league table of every finished season. Rows are kept when a team is
+-- removed so that its honours are not lost.
+CREATE TABLE season_standings (
+    season INT NOT NULL,
+    position INT NOT NULL,
+    team_name VARCHAR(100) NOT NULL,
+    played INT NOT NULL DEFAULT 0,
+    won INT NOT NULL DEFAULT 0,
+    drawn INT NOT NULL DEFAULT 0,
+    lost INT NOT NULL DEFAULT 0,
+    goals_for INT NOT NULL DEFAULT 0,
+    goals_against INT NOT NULL DEFAULT 0,
+    points INT NOT NULL DEFAULT 0,
+    archived_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (season, team_name)
+);
+
+-- Seasons loaded from real results files. They only hold the matches between
+-- league teams, so they are never treated as complete league seasons.
+CREATE TABLE imported_seasons (
+    season INT PRIMARY KEY,
+    label VARCHAR(20) NOT NULL,
+    matches INT NOT NULL,
+    weeks INT NOT NULL,
+    first_date DATE NOT NULL,
+    last_date DATE NOT NULL,
+    imported_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_imported_seasons_label (label)
+);
+
+-- Names that imported results files use for a team, such as "Man United"
+CREATE TABLE team_aliases (
+    alias VARCHAR(100) PRIMARY KEY,
+    team_name VARCHAR(100) NOT NULL,
+    FOREIGN KEY (team_name) REFERENCES teams(name) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE snapshots (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    version INT NOT NULL,
+    week INT NOT NULL,
+    document LONGTEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Saved prediction backtest reports; document holds the full report as JSON
+CREATE TABLE backtest_reports (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    model VARCHAR(50) NOT NULL,
+    predictions INT NOT NULL,
+    accuracy DOUBLE NOT NULL,
+    brier_score DOUBLE NOT NULL,
+    log_loss DOUBLE NOT NULL,
+    document LONGTEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_name (name)
+);
+
+-- League-wide options such as the playoff configuration, stored as JSON values
+CREATE TABLE league_settings (
+    name VARCHAR(100) PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+CREATE TABLE cups (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    legs INT NOT NULL DEFAULT 1,
+    draw_method VARCHAR(20) NOT NULL DEFAULT 'seeded',
+    current_round INT DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'awaiting_draw',
+    champion_name VARCHAR(100) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+CREATE TABLE cup_entrants (
+    cup_id INT NOT NULL,
+    team_name VARCHAR(100) NOT NULL,
+    seed INT NOT NULL,
+    PRIMARY KEY (cup_id, team_name),
+    FOREIGN KEY (cup_id) REFERENCES cups(id) ON DELETE CASCADE,
+    FOREIGN KEY (team_name) REFERENCES teams(name) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Leg goals are stored from the point of view of that leg's home side;
+-- extra time and penalties from the point of view of the tie's home team.
+CREATE TABLE cup_ties (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    cup_id INT NOT NULL,
+    round INT NOT NULL,
+    slot INT NOT NULL,
+    home_team_name VARCHAR(100) NOT NULL,
+    away_team_name VARCHAR(100) NULL,
+    leg1_home_goals INT NULL,
+    leg1_away_goals INT NULL,
+    leg2_home_goals INT NULL,
+    leg2_away_goals INT NULL,
+    extra_time_home_goals INT NULL,
+    extra_time_away_goals INT NULL,
+    penalties_home INT NULL,
+    penalties_away INT NULL,
+    winner_name VARCHAR(100) NULL,
+    is_bye BOOLEAN DEFAULT FALSE,
+    played BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_cup_round (cup_id, round),
+    FOREIGN KEY (cup_id) REFERENCES cups(id) ON DELETE CASCADE
+);
+
+CREATE TABLE tournaments (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    group_count INT NOT NULL,
+    qualifiers_per_group INT NOT NULL DEFAULT 2,
+    extra_qualifiers INT NOT NULL DEFAULT 0,
+    knockout_legs INT NOT NULL DEFAULT 1,
+    status VARCHAR(20) NOT NULL DEFAULT 'awaiting_draw',
+    current_matchday INT DEFAULT 0,
+    cup_id INT NULL,
+    champion_name VARCHAR(100) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (cup_id) REFERENCES cups(id) ON DELETE SET NULL
+);
+
+CREATE TABLE tournament_entrants (
+    tournament_id INT NOT NULL,
+    team_name VARCHAR(100) NOT NULL,
+    seed INT NOT NULL,
+    PRIMARY KEY (tournament_id, team_name),
+    FOREIGN KEY (tournament_id) REFERENCES tournaments(id) ON DELETE CASCADE,
+    FOREIGN KEY (team_name) REFERENCES teams(name) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE tournament_groups (
+    tournament_id INT NOT NULL,
+    group_name VARCHAR(10) NOT NULL,
+    team_name VARCHAR(100) NOT NULL,
+    pot INT NOT NULL,
+    PRIMARY KEY (tournament_id, team_name),
+    FOREIGN KEY (tournament_id) REFERENCES tournaments(id) ON DELETE CASCADE,
+    FOREIGN KEY (team_name) REFERENCES teams(name) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE tournament_matches (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    tournament_id INT NOT NULL,
+    group_name VARCHAR(10) NOT NULL,
+    matchday INT NOT NULL,
+    home_team_name VARCHAR(100) NOT NULL,
+    away_team_name VARCHAR(100) NOT NULL,
+    home_goals INT DEFAULT 0,
+    away_goals INT DEFAULT 0,
+    played BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_tournament_matchday (tournament_id, matchday),
+    FOREIGN KEY (tournament_id) REFERENCES tournaments(id) ON DELETE CASCADE
+);
+
+CREATE TABLE divisions (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    tier INT NOT NULL UNIQUE,
+    promotion_spots INT NOT NULL DEFAULT 0,
+    relegation_spots INT NOT NULL DEFAULT 0,
+    playoff_spots INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE division_members (
+    season INT NOT NULL,
+    division_id INT NOT NULL,
+    team_name VARCHAR(100) NOT NULL,
+    PRIMARY KEY (season, team_name),
+    INDEX idx_season_division (season, division_id),
+    FOREIGN KEY (division_id) REFERENCES divisions(id) ON DELETE CASCADE,
+    FOREIGN KEY (team_name) REFERENCES teams(name) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE division_matches (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    season INT NOT NULL,
+    division_id INT NOT NULL,
+    week INT NOT NULL,
+    home_team_name VARCHAR(100) NOT NULL,
+    away_team_name VARCHAR(100) NOT NULL,
+    home_goals INT DEFAULT 0,
+    away_goals INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_season_division (season, division_id),
+    FOREIGN KEY (division_id) REFERENCES divisions(id) ON DELETE CASCADE
+);
+
+CREATE TABLE division_movements (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    season INT NOT NULL,
+    team_name VARCHAR(100) NOT NULL,
+    from_division_id INT NOT NULL,
+    to_division_id INT NOT NULL,
+    movement VARCHAR(20) NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_season (season),
+    FOREIGN KEY (from_division_id) REFERENCES divisions(id) ON DELETE CASCADE,
+    FOREIGN KEY (to_division_id) REFERENCES divisions(id) ON DELETE CASCADE
+);
+
 -- Insert default teams
 INSERT INTO teams (name, strength) VALUES 
 ('Lions', 90),